@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// renameSpec is the declarative input accepted by --config. Besides rules, it accepts the
+// inventory printed by the discover command, renaming every interface that has a newName.
+type renameSpec struct {
+	MCName string          `yaml:"mcName"`
+	Rules  []specRule      `yaml:"rules"`
+	Nodes  []inventoryNode `yaml:"nodes"`
+}
+
+// specRule mirrors the matching and naming flags of the root command
+type specRule struct {
+	MACs       []string `yaml:"macs"`
+	MACMatch   string   `yaml:"macMatch"`
+	Names      []string `yaml:"names"`
+	NamePolicy string   `yaml:"namePolicy"`
+	Vendor     string   `yaml:"vendor"`
+	Model      string   `yaml:"model"`
+	Driver     string   `yaml:"driver"`
+	PCIPaths   []string `yaml:"pciPaths"`
+	PortKey    string   `yaml:"portKey"`
+}
+
+// loadRenameSpec reads a rename spec file and validates every rule with the same
+// checks that are applied to command-line flags
+func loadRenameSpec(path string) (*renameSpec, []ruleInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var spec renameSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	inventoryInputs, err := inventoryRuleInputs(spec.Nodes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	if len(spec.Rules) == 0 && len(inventoryInputs) == 0 {
+		return nil, nil, fmt.Errorf("config file %s does not contain any rules or interfaces with a newName", path)
+	}
+
+	inputs := make([]ruleInput, 0, len(spec.Rules)+len(inventoryInputs))
+	for i := range spec.Rules {
+		input, err := spec.Rules[i].toRuleInput()
+		if err != nil {
+			return nil, nil, fmt.Errorf("config file %s: rule %d: %w", path, i+1, err)
+		}
+		if err := validateInputs(&input); err != nil {
+			return nil, nil, fmt.Errorf("config file %s: rule %d: %w", path, i+1, err)
+		}
+		inputs = append(inputs, input)
+	}
+
+	return &spec, append(inputs, inventoryInputs...), nil
+}
+
+// inventoryRuleInputs turns the interfaces of a discover inventory that have a newName into
+// MAC address rules. Interfaces given the same name on different nodes share one .link file
+// listing all their MAC addresses, matched as permanent addresses when the interfaces have one.
+func inventoryRuleInputs(nodes []inventoryNode) ([]ruleInput, error) {
+	var names []string
+	macsByName := map[string][]string{}
+	permanentByName := map[string]bool{}
+	nodeByName := map[string]map[string]string{}
+
+	for _, node := range nodes {
+		for i := range node.Interfaces {
+			iface := &node.Interfaces[i]
+			newName := strings.TrimSpace(iface.NewName)
+			if newName == "" {
+				continue
+			}
+
+			// The permanent address is what udev sees when the .link file is applied,
+			// before bonding or other software changes the current one
+			mac, permanent := iface.PermanentMACAddress, iface.PermanentMACAddress != ""
+			if !permanent {
+				mac = iface.MACAddress
+			}
+			if mac == "" {
+				return nil, fmt.Errorf("node %s: interface %s has no MAC address", node.Node, iface.Name)
+			}
+			mac, err := machineconfig.NormalizeMACAddress(mac)
+			if err != nil {
+				return nil, fmt.Errorf("node %s: interface %s: %w", node.Node, iface.Name, err)
+			}
+
+			if nodeByName[node.Node] == nil {
+				nodeByName[node.Node] = map[string]string{}
+			}
+			if previous, ok := nodeByName[node.Node][newName]; ok {
+				return nil, fmt.Errorf("node %s: newName %s is set on both %s and %s", node.Node, newName, previous, iface.Name)
+			}
+			nodeByName[node.Node][newName] = iface.Name
+
+			if _, ok := macsByName[newName]; !ok {
+				names = append(names, newName)
+				permanentByName[newName] = permanent
+			} else if permanentByName[newName] != permanent {
+				// A .link file matches either permanent or current addresses, not a mix
+				return nil, fmt.Errorf("node %s: interface %s %s, unlike the other interfaces named %s; give them different newNames",
+					node.Node, iface.Name, describePermanentMAC(permanent), newName)
+			}
+			macsByName[newName] = append(macsByName[newName], mac)
+		}
+	}
+
+	inputs := make([]ruleInput, 0, len(names))
+	for _, name := range names {
+		inputs = append(inputs, ruleInput{
+			macs:     []string{strings.Join(macsByName[name], " ")},
+			macMatch: inventoryMACMatch(permanentByName[name]),
+			names:    []string{name},
+		})
+	}
+	return inputs, nil
+}
+
+func inventoryMACMatch(permanent bool) machineconfig.MACMatch {
+	if permanent {
+		return machineconfig.MACMatchPermanent
+	}
+	return machineconfig.MACMatchCurrent
+}
+
+func describePermanentMAC(permanent bool) string {
+	if permanent {
+		return "has a permanent MAC address"
+	}
+	return "has no permanent MAC address"
+}
+
+func (r *specRule) toRuleInput() (ruleInput, error) {
+	pciPaths, err := normalizePCIPaths(trimAll(r.PCIPaths))
+	if err != nil {
+		return ruleInput{}, err
+	}
+
+	portKey, err := machineconfig.ParsePortKey(r.PortKey)
+	if err != nil {
+		return ruleInput{}, err
+	}
+
+	macs, err := normalizeMACAddresses(trimAll(r.MACs))
+	if err != nil {
+		return ruleInput{}, err
+	}
+
+	match, err := machineconfig.ParseMACMatch(r.MACMatch)
+	if err != nil {
+		return ruleInput{}, err
+	}
+
+	policy, err := normalizeNamePolicy(r.NamePolicy)
+	if err != nil {
+		return ruleInput{}, err
+	}
+
+	return ruleInput{
+		macs:     macs,
+		macMatch: match,
+		names:    trimAll(r.Names),
+		policy:   policy,
+		vendor:   strings.TrimSpace(r.Vendor),
+		model:    strings.TrimSpace(r.Model),
+		driver:   strings.TrimSpace(r.Driver),
+		pciPaths: pciPaths,
+		portKey:  portKey,
+	}, nil
+}
+
+// trimAll trims whitespace from every value and drops empty ones
+func trimAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"github.com/spf13/cobra"
+)
+
+const formatText = "text"
+
+var (
+	decodeMCName string
+	decodeFormat string
+)
+
+// decodedMachineConfig is the decode output of one MachineConfig
+type decodedMachineConfig struct {
+	Name  string                          `json:"name"`
+	Files []machineconfig.DecodedLinkFile `json:"files"`
+}
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode [FILE|-]",
+	Short: "Decode the .link files of existing MachineConfigs",
+	Long: `Read MachineConfigs from a YAML file, from stdin with '-', or from the cluster
+with --mc-name, decode the data URL of every /etc/systemd/network/*.link file
+and print their [Match] and [Link] sections. Both URL-encoded and base64 data
+URLs are handled. Other documents of a YAML stream are skipped.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runDecode,
+}
+
+func init() {
+	addClusterFlags(decodeCmd.Flags())
+	decodeCmd.Flags().StringVar(&decodeMCName, "mc-name", "", "Name of the MachineConfig to read from the cluster")
+	decodeCmd.Flags().StringVar(&decodeFormat, "format", formatText, "Output format: text or json")
+	rootCmd.AddCommand(decodeCmd)
+}
+
+func runDecode(cmd *cobra.Command, args []string) error {
+	if decodeFormat != formatText && decodeFormat != formatJSON {
+		return fmt.Errorf("invalid --format %q: must be text or json", decodeFormat)
+	}
+	if (len(args) == 0) == (decodeMCName == "") {
+		return fmt.Errorf("specify either a file ('-' for stdin) or --mc-name")
+	}
+
+	configs, err := readDecodeInput(cmd.InOrStdin(), args)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("no MachineConfig found in the input")
+	}
+
+	decoded := make([]decodedMachineConfig, 0, len(configs))
+	for _, mc := range configs {
+		files, err := machineconfig.DecodeLinkFiles(mc)
+		if err != nil {
+			return fmt.Errorf("MachineConfig %s: %w", mc.Metadata.Name, err)
+		}
+		decoded = append(decoded, decodedMachineConfig{Name: mc.Metadata.Name, Files: files})
+	}
+
+	if decodeFormat == formatJSON {
+		data, err := json.MarshalIndent(decoded, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal decoded files: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printDecoded(decoded)
+	return nil
+}
+
+// readDecodeInput reads the MachineConfigs from the file argument, stdin or the cluster
+func readDecodeInput(stdin io.Reader, args []string) ([]*machineconfig.MachineConfig, error) {
+	if decodeMCName != "" {
+		mc, err := machineconfig.GetMachineConfig(context.Background(), getKubeconfigPath(), decodeMCName)
+		if err != nil {
+			return nil, err
+		}
+		if mc == nil {
+			return nil, fmt.Errorf("MachineConfig %s does not exist on the cluster", decodeMCName)
+		}
+		return []*machineconfig.MachineConfig{mc}, nil
+	}
+
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MachineConfig: %w", err)
+	}
+
+	return machineconfig.UnmarshalMachineConfigs(data)
+}
+
+func printDecoded(decoded []decodedMachineConfig) {
+	for i, mc := range decoded {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("MachineConfig: %s\n", mc.Name)
+		if len(mc.Files) == 0 {
+			fmt.Println("  (no .link files)")
+		}
+
+		for _, file := range mc.Files {
+			fmt.Printf("\n%s\n", file.Path)
+			for j, section := range file.Sections {
+				if j > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("  [%s]\n", section.Name)
+				for _, entry := range section.Entries {
+					fmt.Printf("  %s=%s\n", entry.Key, entry.Value)
+				}
+			}
+		}
+	}
+}
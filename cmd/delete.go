@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteManaged bool
+	assumeYes     bool
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete generated MachineConfigs from the cluster",
+	Long: `Delete a rename MachineConfig by --mc-name, or every MachineConfig generated by
+this tool with --managed. The .link files that will disappear and the
+MachineConfigPool that will roll out the change are shown before asking for
+confirmation. Deleting a MachineConfig reboots the nodes of its pool.`,
+	SilenceUsage: true,
+	RunE:         runDelete,
+}
+
+func init() {
+	addClusterFlags(deleteCmd.Flags())
+	deleteCmd.Flags().StringVar(&mcName, "mc-name", defaultMCName, "Name of the MachineConfig resource to delete")
+	deleteCmd.Flags().BoolVar(&deleteManaged, "managed", false, fmt.Sprintf("Delete every MachineConfig labelled %s=%s", machineconfig.ManagedByLabel, machineconfig.ManagedByValue))
+	deleteCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	if deleteManaged && cmd.Flags().Changed("mc-name") {
+		return fmt.Errorf("--managed and --mc-name are mutually exclusive")
+	}
+
+	kubeconfigPath := getKubeconfigPath()
+	ctx := context.Background()
+
+	targets, err := findDeleteTargets(ctx, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No MachineConfigs to delete.")
+		return nil
+	}
+
+	pools, err := machineconfig.ListMachineConfigPools(ctx, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range targets {
+		if err := printDeleteTarget(mc, pools); err != nil {
+			return err
+		}
+	}
+
+	if !assumeYes && !confirm("Do you want to delete these MachineConfigs from the cluster?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	for _, mc := range targets {
+		if err := machineconfig.DeleteMachineConfig(ctx, kubeconfigPath, mc.Metadata.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nNote: The Machine Config Operator will roll out this change to the nodes.")
+	fmt.Println("This may take several minutes and will cause node reboots.")
+
+	return nil
+}
+
+func findDeleteTargets(ctx context.Context, kubeconfigPath string) ([]*machineconfig.MachineConfig, error) {
+	if deleteManaged {
+		selector := fmt.Sprintf("%s=%s", machineconfig.ManagedByLabel, machineconfig.ManagedByValue)
+		return machineconfig.ListMachineConfigs(ctx, kubeconfigPath, selector)
+	}
+
+	mc, err := machineconfig.GetMachineConfig(ctx, kubeconfigPath, mcName)
+	if err != nil {
+		return nil, err
+	}
+	if mc == nil {
+		return nil, fmt.Errorf("MachineConfig '%s' not found", mcName)
+	}
+
+	return []*machineconfig.MachineConfig{mc}, nil
+}
+
+// printDeleteTarget shows the pools that will roll and the .link files that will disappear
+func printDeleteTarget(mc *machineconfig.MachineConfig, pools []*machineconfig.PoolStatus) error {
+	var selecting []string
+	for _, pool := range pools {
+		selected, err := pool.SelectsMachineConfig(mc.Metadata.Labels)
+		if err != nil {
+			return err
+		}
+		if selected {
+			selecting = append(selecting, pool.Name)
+		}
+	}
+
+	affected := strings.Join(selecting, ", ")
+	if affected == "" {
+		affected = "none"
+	}
+	fmt.Printf("\nMachineConfig '%s' (pools: %s)\n", mc.Metadata.Name, affected)
+
+	if mc.Metadata.Labels[machineconfig.ManagedByLabel] != machineconfig.ManagedByValue {
+		fmt.Println("  ⚠️  not generated by ocp-rename-interfaces")
+	}
+	if len(selecting) == 0 {
+		fmt.Println("  ⚠️  no MachineConfigPool selects it, deleting it changes no node")
+	}
+
+	for _, path := range machineconfig.LinkFilePaths(mc) {
+		fmt.Printf("  - %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"github.com/spf13/cobra"
+)
+
+// errDifferencesFound makes the diff command exit non-zero when the cluster is out of date
+var errDifferencesFound = errors.New("generated MachineConfig differs from the cluster")
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the generated MachineConfig with the one on the cluster",
+	Long: `Fetch the existing MachineConfig by name, decode its .link files and print a
+unified diff per file against the freshly generated MachineConfig. Exits with a
+non-zero status when there are differences.`,
+	SilenceUsage: true,
+	RunE:         runDiff,
+}
+
+func init() {
+	addRuleFlags(diffCmd.Flags())
+	addClusterFlags(diffCmd.Flags())
+	addTargetFlags(diffCmd.Flags())
+	addIgnitionFlags(diffCmd.Flags())
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	req, err := parseAndValidateFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := validateIgnitionFlag(); err != nil {
+		return err
+	}
+
+	kubeconfigPath := getKubeconfigPath()
+	ctx := context.Background()
+
+	isSingleNode, _, err := machineconfig.IsClusterSingleNode(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect cluster topology: %w", err)
+	}
+
+	if err := detectIgnitionVersion(ctx, kubeconfigPath, targetPool(isSingleNode)); err != nil {
+		return err
+	}
+
+	generated, err := generateMachineConfig(isSingleNode, req)
+	if err != nil {
+		return err
+	}
+
+	existing, err := machineconfig.GetMachineConfig(ctx, kubeconfigPath, generated.Metadata.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		fmt.Printf("MachineConfig '%s' does not exist on the cluster\n\n", generated.Metadata.Name)
+	}
+
+	differences := printMachineConfigDiff(existing, generated)
+
+	diffs, err := machineconfig.DiffMachineConfigs(existing, generated)
+	if err != nil {
+		return err
+	}
+
+	for _, diff := range diffs {
+		if diff.Unified != "" {
+			fmt.Print(diff.Unified)
+			differences = true
+		}
+	}
+
+	if differences {
+		return errDifferencesFound
+	}
+
+	fmt.Printf("MachineConfig '%s' is up to date\n", generated.Metadata.Name)
+	return nil
+}
+
+// printMachineConfigDiff reports differences outside of the .link files
+func printMachineConfigDiff(existing, generated *machineconfig.MachineConfig) bool {
+	if existing == nil {
+		return true
+	}
+
+	differences := false
+
+	keys := make([]string, 0, len(generated.Metadata.Labels))
+	for key := range generated.Metadata.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if value := generated.Metadata.Labels[key]; existing.Metadata.Labels[key] != value {
+			fmt.Printf("label %s: %q -> %q\n", key, existing.Metadata.Labels[key], value)
+			differences = true
+		}
+	}
+
+	if existing.Spec.Config.Ignition.Version != generated.Spec.Config.Ignition.Version {
+		fmt.Printf("ignition version: %s -> %s\n", existing.Spec.Config.Ignition.Version, generated.Spec.Config.Ignition.Version)
+		differences = true
+	}
+
+	return differences
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+var (
+	discoverPool     string
+	discoverSelector string
+	discoverFormat   string
+)
+
+// inventory is the output of the discover command. Filling in newName and passing the
+// file to --config generates a rule per renamed interface.
+type inventory struct {
+	Nodes []inventoryNode `json:"nodes" yaml:"nodes"`
+}
+
+type inventoryNode struct {
+	Node string `json:"node" yaml:"node"`
+	// Pool optionally names the custom MachineConfigPool of the node with --per-pool
+	Pool       string               `json:"pool,omitempty" yaml:"pool,omitempty"`
+	Interfaces []inventoryInterface `json:"interfaces" yaml:"interfaces"`
+}
+
+type inventoryInterface struct {
+	machineconfig.NetworkInterface `yaml:",inline"`
+	NewName                        string `json:"newName" yaml:"newName"`
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List the network interfaces of every node in a pool",
+	Long: `Walk all nodes of a MachineConfigPool, or the nodes matching a label selector,
+and collect the name, MAC address, permanent MAC address, driver, PCI path and
+vendor/model IDs of every physical interface. The JSON and YAML inventories can
+be edited to set newName on interfaces and passed back with --config.`,
+	SilenceUsage: true,
+	RunE:         runDiscover,
+}
+
+func init() {
+	addClusterFlags(discoverCmd.Flags())
+	addNodeExecFlags(discoverCmd.Flags())
+	discoverCmd.Flags().StringVar(&discoverPool, "pool", "", "MachineConfigPool whose nodes are inspected (default: worker, or master on single-node and compact clusters)")
+	discoverCmd.Flags().StringVarP(&discoverSelector, "selector", "l", "", "Label selector of the nodes to inspect instead of a pool (e.g., node-role.kubernetes.io/worker=)")
+	discoverCmd.Flags().StringVar(&discoverFormat, "format", formatTable, "Output format: table, json or yaml")
+	rootCmd.AddCommand(discoverCmd)
+}
+
+func runDiscover(cmd *cobra.Command, args []string) error {
+	if discoverPool != "" && discoverSelector != "" {
+		return fmt.Errorf("--pool and --selector are mutually exclusive")
+	}
+
+	switch discoverFormat {
+	case formatTable, formatJSON, formatYAML:
+	default:
+		return fmt.Errorf("invalid --format %q: must be table, json or yaml", discoverFormat)
+	}
+
+	kubeconfigPath := getKubeconfigPath()
+
+	nodeNames, err := discoverNodes(context.Background(), kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	inv := inventory{Nodes: make([]inventoryNode, 0, len(nodeNames))}
+	failedNodes := 0
+
+	for _, nodeName := range nodeNames {
+		fmt.Fprintf(os.Stderr, "Inspecting node %s...\n", nodeName)
+
+		output, err := runOnNode(kubeconfigPath, nodeName, "sh", "-c", machineconfig.InterfaceDumpScript)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping node %s: %v\n", nodeName, err)
+			failedNodes++
+			continue
+		}
+
+		node := inventoryNode{Node: nodeName}
+		interfaces := machineconfig.ParseInterfaceDump(output)
+		for i := range interfaces {
+			node.Interfaces = append(node.Interfaces, inventoryInterface{NetworkInterface: interfaces[i]})
+		}
+		inv.Nodes = append(inv.Nodes, node)
+	}
+
+	if err := printInventory(&inv); err != nil {
+		return err
+	}
+
+	if failedNodes > 0 {
+		return fmt.Errorf("failed to inspect %d of %d node(s)", failedNodes, len(nodeNames))
+	}
+	return nil
+}
+
+// discoverNodes returns the names of the nodes selected by --selector or --pool
+func discoverNodes(ctx context.Context, kubeconfigPath string) ([]string, error) {
+	nodes, err := listDiscoverNodes(ctx, kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found")
+	}
+
+	names := make([]string, 0, len(nodes))
+	for i := range nodes {
+		names = append(names, nodes[i].Name)
+	}
+	return names, nil
+}
+
+func listDiscoverNodes(ctx context.Context, kubeconfigPath string) ([]corev1.Node, error) {
+	if discoverSelector != "" {
+		return machineconfig.ListNodes(ctx, kubeconfigPath, discoverSelector)
+	}
+
+	pool := discoverPool
+	if pool == "" {
+		isSingleNode, _, err := machineconfig.IsClusterSingleNode(kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect cluster topology: %w", err)
+		}
+		pool = roleFor(isSingleNode)
+	}
+
+	return machineconfig.ListPoolNodes(ctx, kubeconfigPath, pool)
+}
+
+func printInventory(inv *inventory) error {
+	switch discoverFormat {
+	case formatJSON:
+		data, err := json.MarshalIndent(inv, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal inventory: %w", err)
+		}
+		fmt.Println(string(data))
+	case formatYAML:
+		data, err := yaml.Marshal(inv)
+		if err != nil {
+			return fmt.Errorf("failed to marshal inventory: %w", err)
+		}
+		fmt.Print(string(data))
+	default:
+		writer := newTableWriter()
+		fmt.Fprintln(writer, "NODE\tNAME\tMAC\tPERMANENT MAC\tDRIVER\tPCI PATH\tVENDOR\tMODEL")
+		for _, node := range inv.Nodes {
+			for i := range node.Interfaces {
+				iface := &node.Interfaces[i]
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", node.Node, iface.Name, iface.MACAddress,
+					orDash(iface.PermanentMACAddress), orDash(iface.Driver), orDash(iface.PCIPath), orDash(iface.VendorID), orDash(iface.ModelID))
+			}
+		}
+		return writer.Flush()
+	}
+	return nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+)
+
+// mergeRequested reports whether the rules are merged into an existing MachineConfig
+func mergeRequested() bool {
+	return mergeExisting || mergeFrom != ""
+}
+
+// mergeWithExisting merges the generated MachineConfig into the existing one of the same name,
+// read from --merge-from or fetched from the cluster
+func mergeWithExisting(mc *machineconfig.MachineConfig) (*machineconfig.MachineConfig, error) {
+	existing, err := loadMergeBase(mc.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		fmt.Fprintf(os.Stderr, "MachineConfig '%s' does not exist on the cluster, nothing to merge\n", mc.Metadata.Name)
+		return mc, nil
+	}
+
+	merged, err := machineconfig.MergeMachineConfigs(existing, mc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge into MachineConfig %s: %w", existing.Metadata.Name, err)
+	}
+	return merged, nil
+}
+
+// loadMergeBase returns the MachineConfig to merge into, or nil when it does not exist on the cluster.
+// A --merge-from file holding a single MachineConfig is used whatever its name.
+func loadMergeBase(name string) (*machineconfig.MachineConfig, error) {
+	if mergeFrom == "" {
+		return machineconfig.GetMachineConfig(context.Background(), getKubeconfigPath(), name)
+	}
+
+	data, err := os.ReadFile(mergeFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --merge-from file: %w", err)
+	}
+	configs, err := machineconfig.UnmarshalMachineConfigs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", mergeFrom, err)
+	}
+
+	if len(configs) == 1 {
+		return configs[0], nil
+	}
+	for _, mc := range configs {
+		if mc.Metadata.Name == name {
+			return mc, nil
+		}
+	}
+	return nil, fmt.Errorf("%s does not contain MachineConfig %s", mergeFrom, name)
+}
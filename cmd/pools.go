@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+)
+
+// hardwarePool is a custom MachineConfigPool for the nodes sharing the same renamed hardware,
+// with the MachineConfig renaming their interfaces. Only the MAC addresses of its own nodes
+// are shipped to them.
+type hardwarePool struct {
+	pool  *machineconfig.MachineConfigPool
+	mc    *machineconfig.MachineConfig
+	nodes []string
+}
+
+// runPerPool generates, and optionally applies, one custom pool and MachineConfig per hardware group
+func runPerPool(req *renameRequest) error {
+	if req.spec == nil || len(req.spec.Nodes) == 0 {
+		return fmt.Errorf("--per-pool requires a discover inventory in --config")
+	}
+	if len(req.spec.Rules) > 0 {
+		return fmt.Errorf("--per-pool cannot be combined with rules in --config, only inventory nodes are used")
+	}
+
+	if !apply {
+		pools, err := buildHardwarePools(req, roleFor(false))
+		if err != nil {
+			return err
+		}
+		return outputHardwarePools(pools)
+	}
+
+	return applyHardwarePools(req)
+}
+
+// buildHardwarePools groups the inventory nodes by the hardware of their renamed interfaces,
+// or by their explicit pool, and builds the pool and MachineConfig of every group
+func buildHardwarePools(req *renameRequest, baseRole string) ([]hardwarePool, error) {
+	groups := map[string][]inventoryNode{}
+	for _, node := range req.spec.Nodes {
+		signature := hardwareSignature(&node)
+		if len(signature) == 0 {
+			continue
+		}
+
+		name := node.Pool
+		if name == "" {
+			name = machineconfig.HardwarePoolName(baseRole, signature)
+		}
+		if name == roleFor(false) || name == roleFor(true) {
+			return nil, fmt.Errorf("node %s: pool %s is not a custom pool", node.Node, name)
+		}
+		groups[name] = append(groups[name], node)
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no interfaces with a newName in the inventory")
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pools := make([]hardwarePool, 0, len(names))
+	for _, name := range names {
+		inputs, err := inventoryRuleInputs(groups[name])
+		if err != nil {
+			return nil, err
+		}
+
+		poolRequest := renameRequest{mcName: req.mcName + "-" + name, inputs: inputs}
+		mc, err := machineconfig.NewMachineConfigFromRules(poolRequest.mcName, name, poolRequest.rules())
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", name, err)
+		}
+		if err := setIgnitionVersion(mc); err != nil {
+			return nil, fmt.Errorf("pool %s: %w", name, err)
+		}
+
+		pool := hardwarePool{pool: machineconfig.NewCustomMachineConfigPool(name, baseRole), mc: mc}
+		for _, node := range groups[name] {
+			pool.nodes = append(pool.nodes, node.Node)
+		}
+		pools = append(pools, pool)
+	}
+
+	return pools, nil
+}
+
+// hardwareSignature describes the renamed interfaces of a node without their MAC addresses,
+// so nodes with the same cards in the same slots get the same signature
+func hardwareSignature(node *inventoryNode) []string {
+	var signature []string
+	for i := range node.Interfaces {
+		iface := &node.Interfaces[i]
+		if newName := strings.TrimSpace(iface.NewName); newName != "" {
+			signature = append(signature, strings.Join([]string{newName, iface.Driver, iface.VendorID, iface.ModelID, iface.PCIPath}, "/"))
+		}
+	}
+	return signature
+}
+
+// marshalHardwarePools renders every pool and MachineConfig as a multi-document YAML stream,
+// with the nodes to label in a comment above each pool
+func marshalHardwarePools(pools []hardwarePool) ([]byte, error) {
+	var documents []string
+	for i := range pools {
+		pool := &pools[i]
+
+		poolData, err := machineconfig.MarshalMachineConfigPool(pool.pool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MachineConfigPool: %w", err)
+		}
+		mcData, err := machineconfig.MarshalMachineConfig(pool.mc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MachineConfig: %w", err)
+		}
+
+		header := fmt.Sprintf("# Nodes: %s\n# Label them with: oc label node <node> %s%s=\n",
+			strings.Join(pool.nodes, ", "), machineconfig.NodeRoleLabelPrefix, pool.pool.Metadata.Name)
+		documents = append(documents, header+string(poolData), string(mcData))
+	}
+
+	return []byte(strings.Join(documents, "---\n")), nil
+}
+
+func outputHardwarePools(pools []hardwarePool) error {
+	data, err := marshalHardwarePools(pools)
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, data, machineconfig.DefaultConfigFileMode); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Printf("MachineConfigPools and MachineConfigs written to: %s\n", output)
+		return nil
+	}
+
+	fmt.Print(string(data))
+	return nil
+}
+
+// applyHardwarePools creates the pools and their MachineConfigs before labelling the nodes,
+// so each node moves to a pool whose rendered config already renames its interfaces
+func applyHardwarePools(req *renameRequest) error {
+	kubeconfigPath := getKubeconfigPath()
+
+	isSingleNode, err := detectClusterTopology(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if isSingleNode {
+		return fmt.Errorf("--per-pool requires dedicated worker nodes, custom pools cannot be based on the master pool")
+	}
+
+	ctx := context.Background()
+	if err := detectIgnitionVersion(ctx, kubeconfigPath, roleFor(false)); err != nil {
+		return err
+	}
+
+	pools, err := buildHardwarePools(req, roleFor(false))
+	if err != nil {
+		return err
+	}
+
+	data, err := marshalHardwarePools(pools)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", data)
+
+	if !confirm("Do you want to create these MachineConfigPools, apply their MachineConfigs and label the nodes?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	for i := range pools {
+		if err := applyHardwarePool(ctx, kubeconfigPath, &pools[i]); err != nil {
+			return err
+		}
+	}
+
+	if !waitRollout {
+		fmt.Println("\nNote: The Machine Config Operator will roll out this change to the nodes.")
+		fmt.Println("This may take several minutes and will cause node reboots.")
+		return nil
+	}
+
+	for i := range pools {
+		opts := machineconfig.WaitOptions{Pool: pools[i].pool.Metadata.Name, MachineConfig: pools[i].mc.Metadata.Name, Timeout: waitTimeout}
+		fmt.Printf("\nWaiting up to %s for MachineConfigPool '%s' to roll out the change...\n", opts.Timeout, opts.Pool)
+		if err := machineconfig.WaitForPoolRollout(ctx, kubeconfigPath, opts); err != nil {
+			return err
+		}
+		fmt.Printf("\n✓ MachineConfigPool '%s' updated successfully!\n", opts.Pool)
+	}
+
+	return nil
+}
+
+func applyHardwarePool(ctx context.Context, kubeconfigPath string, pool *hardwarePool) error {
+	if err := machineconfig.ApplyMachineConfigPool(ctx, kubeconfigPath, pool.pool); err != nil {
+		return err
+	}
+
+	if err := machineconfig.ApplyMachineConfig(ctx, kubeconfigPath, pool.mc); err != nil {
+		return fmt.Errorf("failed to apply MachineConfig: %w", err)
+	}
+
+	for _, node := range pool.nodes {
+		if err := machineconfig.LabelNodeForPool(ctx, kubeconfigPath, node, pool.pool.Metadata.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
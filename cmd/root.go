@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	macAddresses   string
+	macMatch       string
 	namePolicy     string
 	interfaceNames string
 	kubeconfig     string
@@ -24,8 +30,62 @@ var (
 	modelID        string
 	refIfName      string
 	node           string
+	configFile     string
+	pciPaths       string
+	driver         string
+	portKey        string
+	waitRollout    bool
+	waitTimeout    time.Duration
+	perPool        bool
+	targetRoleName string
+	targetPoolName string
+	debugImage     string
+	debugNamespace string
+	outputFormat   string
+	outputDir      string
+	ignitionVer    string
+	mergeExisting  bool
+	mergeFrom      string
+	ignoreConflict bool
 )
 
+const (
+	defaultMCName = "50-interface-rename"
+	// defaultWaitTimeout leaves room for every node of a large pool to drain and reboot
+	defaultWaitTimeout = time.Hour
+
+	formatMachineConfig = "machineconfig"
+	formatButane        = "butane"
+	formatLinks         = "links"
+	formatNMState       = "nmstate"
+	formatIgnition      = "ignition"
+)
+
+// outputFormats lists the values accepted by --format
+var outputFormats = []string{formatMachineConfig, formatButane, formatLinks, formatNMState, formatIgnition}
+
+// ruleInput holds the matching and naming options of one rename request, coming
+// either from the command-line flags or from a rule in a --config file
+type ruleInput struct {
+	macs     []string
+	macMatch machineconfig.MACMatch
+	names    []string
+	policy   string
+	vendor   string
+	model    string
+	driver   string
+	pciPaths []string
+	portKey  machineconfig.PortKey
+}
+
+// renameRequest is the validated set of rename rules and the MachineConfig name they render into
+type renameRequest struct {
+	mcName string
+	inputs []ruleInput
+	// spec is the --config file the request was loaded from, nil when flags are used
+	spec *renameSpec
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "ocp-rename-interfaces",
 	Short: "Generate and apply OpenShift MachineConfig for network interface renaming",
@@ -36,17 +96,82 @@ renaming and explicit interface naming.`,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&macAddresses, "macs", "m", "", "Comma-separated list of MAC addresses (e.g., aa:bb:cc:dd:ee:ff,11:22:33:44:55:66)")
-	rootCmd.Flags().StringVarP(&namePolicy, "name-policy", "p", "", "Single NamePolicy scheme (e.g., slot, path, onboard, mac, keep)")
-	rootCmd.Flags().StringVarP(&interfaceNames, "names", "n", "", "Comma-separated list of interface names (e.g., ptp0,ptp1). Must match number of MACs.")
-	rootCmd.Flags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to kubeconfig file (uses KUBECONFIG env or ~/.kube/config if not specified)")
+	addRuleFlags(rootCmd.Flags())
+	addClusterFlags(rootCmd.Flags())
+	addNodeExecFlags(rootCmd.Flags())
+	addTargetFlags(rootCmd.Flags())
 	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (prints to stdout if not specified)")
+	rootCmd.Flags().StringVar(&outputFormat, "format", formatMachineConfig, "Output format: "+strings.Join(outputFormats, ", ")+
+		". nmstate only names NetworkManager profiles, the interfaces keep their kernel names")
+	rootCmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory the .link files are written to with --format links")
+	addIgnitionFlags(rootCmd.Flags())
 	rootCmd.Flags().BoolVarP(&apply, "apply", "a", false, "Apply the MachineConfig to the cluster")
-	rootCmd.Flags().StringVar(&mcName, "mc-name", "50-interface-rename", "Name of the MachineConfig resource")
-	rootCmd.Flags().StringVar(&vendorID, "vendor", "", "Vendor ID in hex format (e.g., 0x8086). Use with --model for property-based matching.")
-	rootCmd.Flags().StringVar(&modelID, "model", "", "Model ID in hex format (e.g., 0x153a). Use with --vendor for property-based matching.")
-	rootCmd.Flags().StringVar(&refIfName, "refIfName", "", "Reference interface name to auto-detect vendor and model IDs using udevadm")
-	rootCmd.Flags().StringVar(&node, "node", "", "Node name for remote vendor/model detection via 'oc debug node'. Use with --refIfName and --kubeconfig.")
+	rootCmd.Flags().BoolVar(&waitRollout, "wait", false, "After --apply, wait for the MachineConfigPool to finish rolling out the change")
+	rootCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", defaultWaitTimeout, "Maximum time to wait for the rollout with --wait")
+	rootCmd.Flags().BoolVar(&perPool, "per-pool", false, "With a discover inventory in --config, create a custom MachineConfigPool and MachineConfig per hardware group")
+	rootCmd.Flags().BoolVar(&mergeExisting, "merge", false, "Merge the rules into the existing MachineConfig of the same name on the cluster, keeping its other interfaces")
+	rootCmd.Flags().BoolVar(&ignoreConflict, "ignore-conflicts", false, "Apply even when .link files of other MachineConfigs of the pool shadow the generated ones")
+	rootCmd.Flags().StringVar(&mergeFrom, "merge-from", "", "Merge the rules into the MachineConfig read from this file instead of the cluster (implies --merge)")
+}
+
+// addRuleFlags registers the matching and naming flags shared by every command that generates a MachineConfig
+func addRuleFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&macAddresses, "macs", "m", "", "Comma-separated list of MAC addresses, with colons, dashes or Cisco dots (e.g., aa:bb:cc:dd:ee:ff,aa-bb-cc-dd-ee-fe)")
+	flags.StringVar(&macMatch, "mac-match", "", "Address --macs are matched against: current (MACAddress=, default), permanent (PermanentMACAddress=, stable on bond members and SR-IOV) or both")
+	flags.StringVarP(&namePolicy, "name-policy", "p", "", "Comma-separated NamePolicy schemes tried in order: kernel, database, onboard, slot, path, mac, keep (e.g., slot,path). With --names, the names are the fallback.")
+	flags.StringVarP(&interfaceNames, "names", "n", "", "Comma-separated list of interface names (e.g., ptp0,ptp1). Must match number of MACs.")
+	flags.StringVar(&mcName, "mc-name", defaultMCName, "Name of the MachineConfig resource")
+	flags.StringVar(&vendorID, "vendor", "", "Vendor ID in hex format (e.g., 0x8086). Use with --model for property-based matching.")
+	flags.StringVar(&modelID, "model", "", "Model ID in hex format (e.g., 0x153a). Use with --vendor for property-based matching.")
+	flags.StringVar(&refIfName, "refIfName", "", "Reference interface name to auto-detect vendor and model IDs using udevadm")
+	flags.StringVar(&node, "node", "", "Node name for remote vendor/model detection via a debug pod. Use with --refIfName and --kubeconfig.")
+	flags.StringVar(&driver, "driver", "", "Kernel driver to match with systemd Driver= (e.g., ice, igc). Can be combined with --vendor/--model.")
+	flags.StringVar(&portKey, "port-key", "", "How ports are told apart when several --names are used with --driver or --vendor/--model: function (PCI function, default) or phys-port (phys_port_name)")
+	flags.StringVar(&pciPaths, "pci-path", "", "Comma-separated list of PCI paths to match with systemd Path= (e.g., pci-0000:3b:00.0). Names are matched to paths in order.")
+	flags.StringVarP(&configFile, "config", "c", "", "Path to a YAML rename spec declaring multiple rules. Cannot be combined with matching or naming flags.")
+	flags.SetNormalizeFunc(normalizeRuleFlagName)
+}
+
+// normalizeRuleFlagName accepts --name-policies as an alias of --name-policy
+func normalizeRuleFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "name-policies" {
+		name = "name-policy"
+	}
+	return pflag.NormalizedName(name)
+}
+
+// addClusterFlags registers the flags needed to talk to the cluster
+func addClusterFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to kubeconfig file (uses KUBECONFIG env or ~/.kube/config if not specified)")
+}
+
+// addTargetFlags registers the flags choosing the role label of the MachineConfig and the pool rolling it out
+func addTargetFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&targetRoleName, "role", "", "Role label of the MachineConfig (default: --pool, or worker, or master on single-node and compact clusters)")
+	flags.StringVar(&targetPoolName, "pool", "", "MachineConfigPool rolling out the MachineConfig, must select its role label (default: --role)")
+}
+
+// addIgnitionFlags registers the flags controlling the generated Ignition config
+func addIgnitionFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&ignitionVer, "ignition-version", "", "Ignition spec version of the generated config, "+
+		strings.Join(machineconfig.SupportedIgnitionVersions, ", ")+" (default: the cluster's version with --apply, else "+machineconfig.DefaultIgnitionVersion+")")
+}
+
+// validateIgnitionFlag checks an explicit --ignition-version before anything is generated
+func validateIgnitionFlag() error {
+	if ignitionVer == "" {
+		return nil
+	}
+	if err := machineconfig.ValidateIgnitionVersion(ignitionVer); err != nil {
+		return fmt.Errorf("--ignition-version: %w", err)
+	}
+	return nil
+}
+
+// addNodeExecFlags registers the flags controlling the debug pod used to run commands on nodes
+func addNodeExecFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&debugImage, "debug-image", "", "Image of the privileged debug pod used to run commands on nodes (default: the cluster's openshift/tools image, as oc debug)")
+	flags.StringVar(&debugNamespace, "debug-namespace", "", "Existing namespace allowing privileged pods in which debug pods are created (default: a temporary namespace, deleted afterwards)")
 }
 
 func Execute() error {
@@ -54,38 +179,165 @@ func Execute() error {
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	macs, names, policy, vendor, model, err := parseAndValidateFlags()
+	req, err := parseAndValidateFlags(cmd)
 	if err != nil {
 		return err
 	}
 
+	warnInterfaceNames(req.rules())
+
+	if waitRollout && !apply {
+		return fmt.Errorf("--wait requires --apply")
+	}
+
+	if err := validateOutputFlags(); err != nil {
+		return err
+	}
+
+	if err := validateIgnitionFlag(); err != nil {
+		return err
+	}
+
+	if perPool {
+		if targetRoleName != "" || targetPoolName != "" {
+			return fmt.Errorf("--per-pool creates its own pools and cannot be combined with --role or --pool")
+		}
+		return runPerPool(req)
+	}
+
 	if apply {
-		return applyToCluster(macs, names, policy, vendor, model)
+		return applyToCluster(req)
 	}
 
-	return generateAndOutput(macs, names, policy, vendor, model)
+	return generateAndOutput(req)
+}
+
+// warnInterfaceNames prints the names that are valid but may not be applied reliably
+func warnInterfaceNames(rules []machineconfig.Rule) {
+	for i := range rules {
+		if warning := machineconfig.InterfaceNameWarning(rules[i].Name); warning != "" {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+		}
+	}
+}
+
+// validateOutputFlags checks --format against the other output and cluster flags
+func validateOutputFlags() error {
+	if !slices.Contains(outputFormats, outputFormat) {
+		return fmt.Errorf("invalid --format %q: must be one of %s", outputFormat, strings.Join(outputFormats, ", "))
+	}
+	if mergeRequested() && (outputFormat == formatNMState || perPool) {
+		return fmt.Errorf("--merge cannot be combined with --format nmstate or --per-pool")
+	}
+	if outputFormat != formatMachineConfig && (apply || perPool) {
+		return fmt.Errorf("--format %s cannot be combined with --apply or --per-pool", outputFormat)
+	}
+	if (outputFormat == formatLinks) != (outputDir != "") {
+		return fmt.Errorf("--format links and --output-dir must be used together")
+	}
+	if outputFormat == formatLinks && output != "" {
+		return fmt.Errorf("--format links writes one file per interface, use --output-dir instead of --output")
+	}
+	return nil
 }
 
-func parseAndValidateFlags() (macs, names []string, policy, vendor, model string, err error) {
+func parseAndValidateFlags(cmd *cobra.Command) (*renameRequest, error) {
+	if configFile != "" {
+		return parseConfigFile(cmd)
+	}
+
 	// Handle vendor/model ID detection
-	vendor, model, err = parseVendorModel()
+	vendor, model, err := parseVendorModel()
 	if err != nil {
-		return nil, nil, "", "", "", err
+		return nil, err
+	}
+
+	paths, err := normalizePCIPaths(parseCommaSeparated(pciPaths))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := machineconfig.ParsePortKey(portKey)
+	if err != nil {
+		return nil, err
+	}
+
+	macs, err := parseMACAddresses(macAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := machineconfig.ParseMACMatch(macMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := parseNamePolicy(namePolicy)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse MAC addresses and naming options
-	macs = parseMACAddresses(macAddresses)
-	policy = strings.TrimSpace(namePolicy)
+	input := ruleInput{
+		macs:     macs,
+		macMatch: match,
+		policy:   policy,
+		vendor:   vendor,
+		model:    model,
+		driver:   strings.TrimSpace(driver),
+		pciPaths: paths,
+		portKey:  key,
+	}
 	if interfaceNames != "" {
-		names = parseCommaSeparated(interfaceNames)
+		input.names = parseCommaSeparated(interfaceNames)
 	}
 
 	// Validate all inputs
-	if err := validateInputs(macs, names, policy, vendor); err != nil {
-		return nil, nil, "", "", "", err
+	if err := validateInputs(&input); err != nil {
+		return nil, err
 	}
 
-	return macs, names, policy, vendor, model, nil
+	return &renameRequest{mcName: defaultConfigName(&input), inputs: []ruleInput{input}}, nil
+}
+
+// defaultConfigName generates a MachineConfig name that includes the driver and vendor/model IDs
+// when matching by hardware and --mc-name was left at its default
+func defaultConfigName(input *ruleInput) string {
+	if mcName != defaultMCName || (input.vendor == "" && input.driver == "") {
+		return mcName
+	}
+
+	var parts []string
+	if input.driver != "" {
+		parts = append(parts, input.driver)
+	}
+	if input.vendor != "" {
+		// Strip 0x prefix for the name
+		parts = append(parts, strings.TrimPrefix(input.vendor, "0x"), strings.TrimPrefix(input.model, "0x"))
+	}
+
+	return "50-interface-" + strings.Join(parts, "-")
+}
+
+func parseConfigFile(cmd *cobra.Command) (*renameRequest, error) {
+	for _, flagName := range []string{"macs", "mac-match", "names", "name-policy", "vendor", "model", "driver", "port-key", "pci-path", "refIfName", "node"} {
+		if cmd.Flags().Changed(flagName) {
+			return nil, fmt.Errorf("--config cannot be combined with --%s", flagName)
+		}
+	}
+
+	spec, inputs, err := loadRenameSpec(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// An explicit --mc-name takes precedence over the name in the spec
+	configName := mcName
+	if spec.MCName != "" && !cmd.Flags().Changed("mc-name") {
+		configName = spec.MCName
+	}
+
+	return &renameRequest{mcName: configName, inputs: inputs, spec: spec}, nil
 }
 
 func parseVendorModel() (vendor, model string, err error) {
@@ -119,7 +371,7 @@ func parseVendorModel() (vendor, model string, err error) {
 
 func detectVendorModel() (vendor, model string, err error) {
 	if node != "" {
-		// Detect from cluster node using oc debug
+		// Detect from cluster node using a debug pod
 		kubeconfigPath := getKubeconfigPath()
 		if kubeconfigPath == "" {
 			return "", "", fmt.Errorf("--node requires --kubeconfig or KUBECONFIG environment variable")
@@ -143,59 +395,124 @@ func detectVendorModel() (vendor, model string, err error) {
 	return vendor, model, nil
 }
 
-func validateInputs(macs, names []string, policy, vendor string) error {
+func validateInputs(input *ruleInput) error {
 	// Check that we have at least one matching method
-	if len(macs) == 0 && vendor == "" {
-		return fmt.Errorf("at least one matching method must be specified: --macs or --vendor/--model")
+	if len(input.macs) == 0 && input.vendor == "" && input.driver == "" && len(input.pciPaths) == 0 {
+		return fmt.Errorf("at least one matching method must be specified: --macs, --pci-path, --driver or --vendor/--model")
+	}
+
+	if len(input.macs) > 0 && (input.vendor != "" || input.driver != "" || len(input.pciPaths) > 0) {
+		return fmt.Errorf("--macs cannot be combined with other matching methods")
+	}
+
+	if (input.vendor != "" && input.model == "") || (input.vendor == "" && input.model != "") {
+		return fmt.Errorf("--vendor and --model must be specified together")
 	}
 
 	// Validate naming inputs
-	if policy == "" && len(names) == 0 {
+	if input.policy == "" && len(input.names) == 0 {
 		return fmt.Errorf("either --name-policy or --names must be specified")
 	}
 
-	if policy != "" && len(names) > 0 {
-		return fmt.Errorf("--name-policy and --names are mutually exclusive")
+	for _, name := range input.names {
+		if err := machineconfig.ValidateInterfaceName(name); err != nil {
+			return fmt.Errorf("--names: %w", err)
+		}
 	}
 
 	// If using --names with MACs, count must match MACs count
-	if len(names) > 0 && len(macs) > 0 && len(names) != len(macs) {
-		return fmt.Errorf("number of names (%d) must match number of MAC addresses (%d)", len(names), len(macs))
+	if len(input.names) > 0 && len(input.macs) > 0 && len(input.names) != len(input.macs) {
+		return fmt.Errorf("number of names (%d) must match number of MAC addresses (%d)", len(input.names), len(input.macs))
 	}
 
-	// If using vendor/model with --names, only one name is expected
-	if vendor != "" && len(names) > 1 {
-		return fmt.Errorf("when using --vendor/--model matching, only one interface name can be specified")
+	// If using --names with PCI paths, count must match PCI paths count
+	if len(input.names) > 0 && len(input.pciPaths) > 0 && len(input.names) != len(input.pciPaths) {
+		return fmt.Errorf("number of names (%d) must match number of PCI paths (%d)", len(input.names), len(input.pciPaths))
 	}
 
 	return nil
 }
 
-func applyToCluster(macs, names []string, policy, vendor, model string) error {
-	kubeconfigPath := getKubeconfigPath()
+// toRules expands a rule input into one machineconfig.Rule per generated .link file
+func (r *ruleInput) toRules() []machineconfig.Rule {
+	base := machineconfig.Rule{
+		VendorID:   r.vendor,
+		ModelID:    r.model,
+		Driver:     r.driver,
+		NamePolicy: r.policy,
+		MACMatch:   r.macMatch,
+	}
 
-	fmt.Printf("Using kubeconfig: %s\n", kubeconfigPath)
-	fmt.Print("\nCluster information:\n")
+	// MAC addresses and PCI paths each identify a single interface, names are matched to them in order
+	switch {
+	case len(r.macs) > 0:
+		rules := make([]machineconfig.Rule, 0, len(r.macs))
+		for i, mac := range r.macs {
+			rule := base
+			rule.MACAddress = mac
+			r.setName(&rule, i)
+			rules = append(rules, rule)
+		}
+		return rules
+	case len(r.pciPaths) > 0:
+		rules := make([]machineconfig.Rule, 0, len(r.pciPaths))
+		for i, pciPath := range r.pciPaths {
+			rule := base
+			rule.PCIPath = pciPath
+			r.setName(&rule, i)
+			rules = append(rules, rule)
+		}
+		return rules
+	case len(r.names) > 1:
+		// Several names for the same card model are assigned to its ports in order
+		rules := make([]machineconfig.Rule, 0, len(r.names))
+		for i := range r.names {
+			rule := base
+			port := i
+			rule.Port = &port
+			rule.PortKey = r.portKey
+			r.setName(&rule, i)
+			rules = append(rules, rule)
+		}
+		return rules
+	default:
+		r.setName(&base, 0)
+		return []machineconfig.Rule{base}
+	}
+}
 
-	isSingleNode, clusterInfo, err := machineconfig.IsClusterSingleNode(kubeconfigPath)
+// setName assigns the name at index, if names were given, to a rule
+func (r *ruleInput) setName(rule *machineconfig.Rule, index int) {
+	if len(r.names) > index {
+		rule.Name = r.names[index]
+	}
+}
+
+func applyToCluster(req *renameRequest) error {
+	kubeconfigPath := getKubeconfigPath()
+
+	isSingleNode, err := detectClusterTopology(kubeconfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to detect cluster topology: %w", err)
+		return err
 	}
 
-	fmt.Println(clusterInfo)
+	ctx := context.Background()
+	pool := targetPool(isSingleNode)
 
-	if isSingleNode {
-		fmt.Println("\n⚠️  Single-node or master schedulable cluster detected - will use 'master' role label")
-	} else {
-		fmt.Println("\n✓ Multi-node cluster detected - will use 'worker' role label")
+	if err := detectIgnitionVersion(ctx, kubeconfigPath, pool); err != nil {
+		return err
 	}
 
 	// Generate with appropriate role
-	mc, err := generateMachineConfig(isSingleNode, macs, names, policy, vendor, model)
+	mc, err := generateMachineConfig(isSingleNode, req)
 	if err != nil {
 		return err
 	}
 
+	if err := validateTargetPool(ctx, kubeconfigPath, pool, mc); err != nil {
+		return err
+	}
+
 	// Display the MachineConfig
 	if err := displayMachineConfig(mc); err != nil {
 		return err
@@ -207,18 +524,160 @@ func applyToCluster(macs, names []string, policy, vendor, model string) error {
 		return nil
 	}
 
+	var waitOpts *machineconfig.WaitOptions
+	if waitRollout {
+		// Record the pool state before applying so the wait can tell the new rollout apart
+		if waitOpts, err = prepareWait(ctx, kubeconfigPath, pool, mc); err != nil {
+			return err
+		}
+	}
+
 	// Apply to cluster
-	if err := machineconfig.ApplyMachineConfig(context.Background(), kubeconfigPath, mc); err != nil {
+	if err := machineconfig.ApplyMachineConfig(ctx, kubeconfigPath, mc); err != nil {
 		return fmt.Errorf("failed to apply MachineConfig: %w", err)
 	}
 
 	fmt.Printf("\n✓ MachineConfig '%s' applied successfully!\n", mc.Metadata.Name)
-	fmt.Println("\nNote: The Machine Config Operator will roll out this change to the nodes.")
-	fmt.Println("This may take several minutes and will cause node reboots.")
 
+	return waitForApply(ctx, kubeconfigPath, waitOpts)
+}
+
+// waitForApply waits for the rollout of an applied MachineConfig when --wait was given
+func waitForApply(ctx context.Context, kubeconfigPath string, waitOpts *machineconfig.WaitOptions) error {
+	if waitOpts == nil {
+		fmt.Println("\nNote: The Machine Config Operator will roll out this change to the nodes.")
+		fmt.Println("This may take several minutes and will cause node reboots.")
+		return nil
+	}
+
+	fmt.Printf("\nWaiting up to %s for MachineConfigPool '%s' to roll out the change...\n", waitOpts.Timeout, waitOpts.Pool)
+	if err := machineconfig.WaitForPoolRollout(ctx, kubeconfigPath, *waitOpts); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✓ MachineConfigPool '%s' updated successfully!\n", waitOpts.Pool)
+	return nil
+}
+
+// detectClusterTopology prints the cluster information and the role label that will be used
+func detectClusterTopology(kubeconfigPath string) (isSingleNode bool, err error) {
+	fmt.Printf("Using kubeconfig: %s\n", kubeconfigPath)
+	fmt.Print("\nCluster information:\n")
+
+	isSingleNode, clusterInfo, err := machineconfig.IsClusterSingleNode(kubeconfigPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect cluster topology: %w", err)
+	}
+
+	fmt.Println(clusterInfo)
+
+	switch {
+	case targetRoleName != "" || targetPoolName != "":
+		fmt.Printf("\n✓ Using '%s' role label and MachineConfigPool '%s'\n", targetRole(isSingleNode), targetPool(isSingleNode))
+	case isSingleNode:
+		fmt.Println("\n⚠️  Single-node or master schedulable cluster detected - will use 'master' role label")
+	default:
+		fmt.Println("\n✓ Multi-node cluster detected - will use 'worker' role label")
+	}
+
+	return isSingleNode, nil
+}
+
+// validateTargetPool checks that the pool exists and renders the MachineConfig without conflicts
+// with the .link files of its other MachineConfigs, and warns when the pool will not roll it out right away
+func validateTargetPool(ctx context.Context, kubeconfigPath, pool string, mc *machineconfig.MachineConfig) error {
+	status, err := machineconfig.GetPoolStatus(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return err
+	}
+
+	selected, err := status.SelectsMachineConfig(mc.Metadata.Labels)
+	if err != nil {
+		return err
+	}
+	if !selected {
+		return fmt.Errorf("MachineConfigPool %s does not select MachineConfigs with %s=%s, use --role to set a role label it selects",
+			pool, machineconfig.RoleLabel, mc.Metadata.Labels[machineconfig.RoleLabel])
+	}
+
+	if status.Paused {
+		if waitRollout {
+			return fmt.Errorf("MachineConfigPool %s is paused, --wait would time out: unpause it or apply without --wait", pool)
+		}
+		fmt.Printf("⚠️  MachineConfigPool %s is paused, the MachineConfig will not roll out until it is unpaused\n", pool)
+	}
+	if status.MachineCount == 0 {
+		fmt.Printf("⚠️  MachineConfigPool %s has no machines, the MachineConfig will not be rolled out to any node\n", pool)
+	}
+
+	return checkLinkConflicts(ctx, kubeconfigPath, status, mc)
+}
+
+// checkLinkConflicts reports the .link files of the other MachineConfigs of the pool that interfere
+// with the generated ones, and fails on those preventing the rename unless --ignore-conflicts is set
+func checkLinkConflicts(ctx context.Context, kubeconfigPath string, status *machineconfig.PoolStatus, mc *machineconfig.MachineConfig) error {
+	others, err := machineconfig.ListPoolMachineConfigs(ctx, kubeconfigPath, status)
+	if err != nil {
+		return err
+	}
+
+	conflicts, err := machineconfig.FindLinkConflicts(mc, others)
+	if err != nil {
+		return err
+	}
+
+	blocking := 0
+	for i := range conflicts {
+		conflict := &conflicts[i]
+		marker := "⚠️ "
+		if conflict.Blocking {
+			marker = "✗"
+			blocking++
+		}
+		fmt.Printf("%s %s %s (%s in MachineConfig %s)\n", marker, conflict.Path, conflict.Message, conflict.OtherPath, conflict.OtherMachineConfig)
+	}
+
+	if blocking > 0 && !ignoreConflict {
+		return fmt.Errorf("%d .link file conflict(s) with other MachineConfigs of MachineConfigPool %s, use --ignore-conflicts to apply anyway",
+			blocking, status.Name)
+	}
 	return nil
 }
 
+// prepareWait captures the rendered config of the target pool before the MachineConfig is applied.
+// If the pool already renders the MachineConfig with the same spec it will not render a new config,
+// so none is required.
+func prepareWait(ctx context.Context, kubeconfigPath, pool string, mc *machineconfig.MachineConfig) (*machineconfig.WaitOptions, error) {
+	status, err := machineconfig.GetPoolStatus(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := machineconfig.GetMachineConfig(ctx, kubeconfigPath, mc.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := status.RenderedConfig
+	if existing != nil && machineconfig.SpecUnchanged(existing, mc) {
+		// A role label change can still move the MachineConfig into the pool
+		selected, err := status.SelectsMachineConfig(existing.Metadata.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if selected {
+			previous = ""
+		}
+	}
+
+	return &machineconfig.WaitOptions{
+		Pool:                   pool,
+		MachineConfig:          mc.Metadata.Name,
+		PreviousRenderedConfig: previous,
+		Timeout:                waitTimeout,
+	}, nil
+}
+
 func displayMachineConfig(mc *machineconfig.MachineConfig) error {
 	yamlData, err := machineconfig.MarshalMachineConfig(mc)
 	if err != nil {
@@ -237,7 +696,12 @@ func displayMachineConfig(mc *machineconfig.MachineConfig) error {
 }
 
 func confirmApply() bool {
-	fmt.Print("\nDo you want to apply this MachineConfig to the cluster? (yes/no): ")
+	return confirm("Do you want to apply this MachineConfig to the cluster?")
+}
+
+// confirm asks a yes/no question on stdin
+func confirm(question string) bool {
+	fmt.Printf("\n%s (yes/no): ", question)
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -248,80 +712,228 @@ func confirmApply() bool {
 	return response == "yes" || response == "y"
 }
 
-func generateAndOutput(macs, names []string, policy, vendor, model string) error {
+func generateAndOutput(req *renameRequest) error {
 	// Generate without applying (default to worker for file generation)
-	mc, err := generateMachineConfig(false, macs, names, policy, vendor, model)
+	mc, err := generateMachineConfig(false, req)
 	if err != nil {
 		return err
 	}
 
-	// Marshal to YAML
-	yamlData, err := machineconfig.MarshalMachineConfig(mc)
+	if outputFormat == formatLinks {
+		return writeLinkFiles(mc)
+	}
+
+	// Marshal in the requested format
+	data, description, err := marshalOutput(req, mc)
 	if err != nil {
-		return fmt.Errorf("failed to marshal MachineConfig: %w", err)
+		return err
 	}
 
 	// Output
 	if output != "" {
-		if err := os.WriteFile(output, yamlData, machineconfig.DefaultConfigFileMode); err != nil {
+		if err := os.WriteFile(output, data, machineconfig.DefaultConfigFileMode); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
-		fmt.Printf("MachineConfig written to: %s\n", output)
+		fmt.Printf("%s written to: %s\n", description, output)
 	} else {
-		fmt.Println(string(yamlData))
+		fmt.Println(string(data))
 	}
 
 	return nil
 }
 
-func generateMachineConfig(isSingleNode bool, macs, names []string, policy, vendor, model string) (*machineconfig.MachineConfig, error) {
-	role := "worker"
-	if isSingleNode {
-		role = "master"
-	}
-
-	// Handle vendor/model-based matching
-	if vendor != "" && model != "" {
-		// Generate a name that includes vendor and model IDs if using default
-		configName := mcName
-		if mcName == "50-interface-rename" {
-			// Strip 0x prefix for the name
-			vendorHex := strings.TrimPrefix(vendor, "0x")
-			modelHex := strings.TrimPrefix(model, "0x")
-			configName = fmt.Sprintf("50-interface-%s-%s", vendorHex, modelHex)
-		}
+// marshalNMState expresses the rules as an NMState policy for the nodes of the MachineConfig's role
+func marshalNMState(req *renameRequest, mc *machineconfig.MachineConfig) ([]byte, error) {
+	fmt.Fprintf(os.Stderr, "⚠️  %s\n", machineconfig.NMStateRenameWarning)
 
-		if len(names) > 0 {
-			return machineconfig.NewMachineConfigWithPropertyAndName(configName, role, vendor, model, names[0])
-		}
-		return machineconfig.NewMachineConfigWithPropertyAndPolicy(configName, role, vendor, model, policy)
+	policy, err := machineconfig.NewNodeNetworkConfigurationPolicy(mc.Metadata.Name, mc.Metadata.Labels[machineconfig.RoleLabel], req.rules())
+	if err != nil {
+		return nil, fmt.Errorf("--format nmstate: %w", err)
 	}
 
-	// Handle MAC-based matching
-	if len(names) > 0 {
-		return machineconfig.NewMachineConfigWithExplicitNames(mcName, role, macs, names)
+	data, err := machineconfig.MarshalNodeNetworkConfigurationPolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal NodeNetworkConfigurationPolicy: %w", err)
+	}
+	return data, nil
+}
+
+// writeLinkFiles writes the .link files of the MachineConfig into --output-dir
+func writeLinkFiles(mc *machineconfig.MachineConfig) error {
+	written, err := machineconfig.WriteLinkFiles(mc, outputDir)
+	if err != nil {
+		return err
 	}
 
-	return machineconfig.NewMachineConfigWithPolicy(mcName, role, macs, policy)
+	for _, path := range written {
+		fmt.Printf("Link file written to: %s\n", path)
+	}
+	return nil
 }
 
-// parseMACAddresses parses comma-separated MAC addresses and trims whitespace
-func parseMACAddresses(input string) []string {
-	if input == "" {
-		return []string{}
+// marshalOutput renders the MachineConfig, or its rules, in the --format format and describes what was rendered
+func marshalOutput(req *renameRequest, mc *machineconfig.MachineConfig) (data []byte, description string, err error) {
+	switch outputFormat {
+	case formatButane:
+		description = "Butane config"
+		data, err = machineconfig.MarshalButane(mc)
+	case formatIgnition:
+		description = "Ignition config"
+		data, err = machineconfig.MarshalIgnition(mc)
+	case formatNMState:
+		// Rules NMState cannot express are reported as they are, not as marshalling failures
+		data, err = marshalNMState(req, mc)
+		return data, "NodeNetworkConfigurationPolicy", err
+	default:
+		description = "MachineConfig"
+		data, err = machineconfig.MarshalMachineConfig(mc)
 	}
 
-	parts := strings.Split(input, ",")
-	result := make([]string, 0, len(parts))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal %s: %w", description, err)
+	}
+	return data, description, nil
+}
 
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			result = append(result, trimmed)
+func generateMachineConfig(isSingleNode bool, req *renameRequest) (*machineconfig.MachineConfig, error) {
+	mc, err := machineconfig.NewMachineConfigFromRules(req.mcName, targetRole(isSingleNode), req.rules())
+	if err != nil {
+		return nil, err
+	}
+
+	if mergeRequested() {
+		if mc, err = mergeWithExisting(mc); err != nil {
+			return nil, err
 		}
 	}
 
-	return result
+	if err := setIgnitionVersion(mc); err != nil {
+		return nil, err
+	}
+	return mc, nil
+}
+
+// setIgnitionVersion applies --ignition-version, or the version detected from the cluster, and
+// checks that the files of the MachineConfig can be expressed in it. A merged MachineConfig keeps
+// the version of the existing one unless it is upgraded.
+func setIgnitionVersion(mc *machineconfig.MachineConfig) error {
+	current := mc.Spec.Config.Ignition.Version
+	if mergeRequested() && ignitionVer != "" && machineconfig.ValidateIgnitionVersion(current) == nil &&
+		machineconfig.CompareIgnitionVersions(ignitionVer, current) < 0 {
+		return fmt.Errorf("the existing MachineConfig uses Ignition %s, merging would downgrade it to %s", current, ignitionVer)
+	}
+	if ignitionVer != "" {
+		mc.Spec.Config.Ignition.Version = ignitionVer
+	}
+	return machineconfig.ValidateIgnitionConfig(&mc.Spec.Config)
+}
+
+// detectIgnitionVersion defaults --ignition-version to the version of the pool's rendered config,
+// and rejects an explicit version newer than the cluster supports
+func detectIgnitionVersion(ctx context.Context, kubeconfigPath, pool string) error {
+	rendered, err := machineconfig.RenderedIgnitionVersion(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return err
+	}
+	if machineconfig.ValidateIgnitionVersion(rendered) != nil {
+		// Without a rendered config, or with a version this tool does not know, keep the default
+		return nil
+	}
+
+	if ignitionVer == "" {
+		ignitionVer = rendered
+		fmt.Printf("✓ Using Ignition version %s of the rendered config of MachineConfigPool '%s'\n", rendered, pool)
+		return nil
+	}
+	if machineconfig.CompareIgnitionVersions(ignitionVer, rendered) > 0 {
+		return fmt.Errorf("MachineConfigPool %s renders Ignition %s, its Machine Config Operator does not support --ignition-version %s",
+			pool, rendered, ignitionVer)
+	}
+	return nil
+}
+
+// targetRole returns the role label of the MachineConfig: --role, else --pool, else the role for the cluster topology
+func targetRole(isSingleNode bool) string {
+	if targetRoleName != "" {
+		return targetRoleName
+	}
+	if targetPoolName != "" {
+		return targetPoolName
+	}
+	return roleFor(isSingleNode)
+}
+
+// targetPool returns the MachineConfigPool rolling out the MachineConfig: --pool, else the role label
+func targetPool(isSingleNode bool) string {
+	if targetPoolName != "" {
+		return targetPoolName
+	}
+	return targetRole(isSingleNode)
+}
+
+// roleFor returns the MachineConfig role label, and target pool, for the cluster topology
+func roleFor(isSingleNode bool) string {
+	if isSingleNode {
+		return "master"
+	}
+	return "worker"
+}
+
+// rules expands every rule input of the request
+func (r *renameRequest) rules() []machineconfig.Rule {
+	var rules []machineconfig.Rule
+	for i := range r.inputs {
+		rules = append(rules, r.inputs[i].toRules()...)
+	}
+	return rules
+}
+
+// parseMACAddresses parses comma-separated MAC addresses into their canonical form
+func parseMACAddresses(input string) ([]string, error) {
+	macs, err := normalizeMACAddresses(parseCommaSeparated(input))
+	if err != nil {
+		return nil, fmt.Errorf("--macs: %w", err)
+	}
+	return macs, nil
+}
+
+// parseNamePolicy validates the schemes of --name-policy and returns them space-separated
+func parseNamePolicy(input string) (string, error) {
+	policy, err := normalizeNamePolicy(input)
+	if err != nil {
+		return "", fmt.Errorf("--name-policy: %w", err)
+	}
+	return policy, nil
+}
+
+// normalizeNamePolicy validates an optional list of NamePolicy schemes
+func normalizeNamePolicy(input string) (string, error) {
+	if strings.TrimSpace(input) == "" {
+		return "", nil
+	}
+	return machineconfig.NormalizeNamePolicy(input)
+}
+
+// normalizeMACAddresses converts every MAC address into lowercase colon form and rejects duplicates
+func normalizeMACAddresses(macs []string) ([]string, error) {
+	normalized, err := machineconfig.NormalizeMACAddressList(strings.Join(macs, " "))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(normalized), nil
+}
+
+// normalizePCIPaths converts every PCI path into the form matched by systemd Path=
+func normalizePCIPaths(paths []string) ([]string, error) {
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		normalized, err := machineconfig.NormalizePCIPath(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, normalized)
+	}
+	return result, nil
 }
 
 // parseCommaSeparated parses comma-separated strings and trims whitespace
@@ -376,37 +988,29 @@ func getVendorModelFromInterface(ifName string) (vendorID, modelID string, err e
 	return parseUdevadmOutput(string(output), ifName)
 }
 
-// getVendorModelFromClusterNode executes oc debug node to get vendor and model IDs from a cluster node
+// getVendorModelFromClusterNode runs udevadm in a debug pod to get vendor and model IDs from a cluster node
 func getVendorModelFromClusterNode(kubeconfigPath, nodeName, ifName string) (vendorID, modelID string, err error) {
-	// Build the oc debug node command
-	// Command: oc debug node/<node> --kubeconfig=<path> -- chroot /host udevadm info -q property -p /sys/class/net/<ifName>
 	sysPath := fmt.Sprintf("/sys/class/net/%s", ifName)
 
-	args := []string{
-		"debug",
-		fmt.Sprintf("node/%s", nodeName),
-		fmt.Sprintf("--kubeconfig=%s", kubeconfigPath),
-		"--",
-		"chroot",
-		"/host",
-		"udevadm",
-		"info",
-		"-q",
-		"property",
-		"-p",
-		sysPath,
-	}
-
-	cmd := exec.Command("oc", args...)
-
-	// Execute the command
-	output, err := cmd.CombinedOutput()
+	output, err := runOnNode(kubeconfigPath, nodeName, "udevadm", "info", "-q", "property", "-p", sysPath)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to execute oc debug node: %w (output: %s)", err, string(output))
+		return "", "", err
 	}
 
 	// Parse the output
-	return parseUdevadmOutput(string(output), ifName)
+	return parseUdevadmOutput(output, ifName)
+}
+
+// runOnNode executes a command in the host root filesystem of a cluster node through a privileged
+// debug pod. Interrupting the command still removes the pod.
+func runOnNode(kubeconfigPath, nodeName string, command ...string) (string, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return machineconfig.RunOnNode(ctx, kubeconfigPath, nodeName, machineconfig.NodeExecOptions{
+		Image:     debugImage,
+		Namespace: debugNamespace,
+	}, command...)
 }
 
 // parseUdevadmOutput parses udevadm output to extract vendor and model IDs
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/deliedit/ocp-rename-interfaces/pkg/machineconfig"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that interfaces were renamed on every node of the target pool",
+	Long: `For each node of the target MachineConfigPool, list the network interfaces on the
+node and check that every expected name exists with the expected MAC address,
+PCI path, driver or vendor/model. Prints a per-node pass/fail table and exits
+with a non-zero status on any mismatch. NamePolicy rules are reported as skipped,
+and so are MAC address rules on the nodes that do not have their addresses; they
+fail if no node of the pool has them.`,
+	SilenceUsage: true,
+	RunE:         runVerify,
+}
+
+func init() {
+	addRuleFlags(verifyCmd.Flags())
+	addClusterFlags(verifyCmd.Flags())
+	addNodeExecFlags(verifyCmd.Flags())
+	addTargetFlags(verifyCmd.Flags())
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	req, err := parseAndValidateFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	kubeconfigPath := getKubeconfigPath()
+	ctx := context.Background()
+
+	isSingleNode, _, err := machineconfig.IsClusterSingleNode(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect cluster topology: %w", err)
+	}
+
+	pool := targetPool(isSingleNode)
+	nodes, err := machineconfig.ListPoolNodes(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("MachineConfigPool %s has no nodes", pool)
+	}
+
+	writer := newTableWriter()
+	fmt.Fprintln(writer, "NODE\tEXPECTED\tRESULT\tDETAIL")
+
+	found := listNodeInterfaces(writer, kubeconfigPath, nodes)
+	failedNodes := len(nodes) - len(found)
+
+	results, missing := machineconfig.VerifyPool(req.rules(), found)
+	for i := range found {
+		if !writeVerifyResults(writer, found[i].Node, results[i]) {
+			failedNodes++
+		}
+	}
+	writeVerifyResults(writer, "-", missing)
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if failedNodes > 0 {
+		return fmt.Errorf("verification failed on %d of %d node(s) in MachineConfigPool %s", failedNodes, len(nodes), pool)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d rule(s) match no interface in MachineConfigPool %s", len(missing), pool)
+	}
+
+	fmt.Printf("\n✓ All %d node(s) in MachineConfigPool %s have the expected interfaces\n", len(nodes), pool)
+	return nil
+}
+
+// listNodeInterfaces lists the interfaces of every node, writing a failed row for the nodes it cannot reach
+func listNodeInterfaces(writer io.Writer, kubeconfigPath string, nodes []corev1.Node) []machineconfig.NodeInterfaces {
+	var found []machineconfig.NodeInterfaces
+	for i := range nodes {
+		output, err := runOnNode(kubeconfigPath, nodes[i].Name, "sh", "-c", machineconfig.InterfaceDumpScript)
+		if err != nil {
+			fmt.Fprintf(writer, "%s\t-\t%s\t%v\n", nodes[i].Name, machineconfig.VerifyFail, err)
+			continue
+		}
+		found = append(found, machineconfig.NodeInterfaces{Node: nodes[i].Name, Interfaces: machineconfig.ParseInterfaceDump(output)})
+	}
+	return found
+}
+
+// writeVerifyResults writes one table row per result for a node and reports whether all of them passed
+func writeVerifyResults(writer io.Writer, nodeName string, results []machineconfig.VerifyResult) bool {
+	passed := true
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", nodeName, result.Expected, result.Status, result.Detail)
+		passed = passed && result.Status != machineconfig.VerifyFail
+	}
+	return passed
+}
+
+// newTableWriter returns a writer aligning tab-separated columns on stdout
+func newTableWriter() *tabwriter.Writer {
+	const columnPadding = 2
+	return tabwriter.NewWriter(os.Stdout, 0, 0, columnPadding, ' ', 0)
+}
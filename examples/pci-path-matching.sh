@@ -0,0 +1,12 @@
+#!/bin/bash
+# Example: Generate MachineConfig matching interfaces by PCI path
+# The same MachineConfig works on every node with the same slot topology.
+# Names are matched to PCI paths in order: ptp0→first path, ptp1→second path.
+
+./bin/ocp-rename-interfaces \
+  --pci-path "pci-0000:3b:00.0,pci-0000:3b:00.1" \
+  --names "ptp0,ptp1" \
+  --mc-name "50-ptp-pci-interfaces" \
+  --output "pci-path-interfaces.yaml"
+
+echo "Generated pci-path-interfaces.yaml"
@@ -0,0 +1,100 @@
+package machineconfig
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// butaneVersions maps Ignition spec versions to the oldest openshift Butane variant version
+// that butane renders into them: 4.8.0 to 4.13.0 render Ignition 3.2.0, 4.14.0 and newer 3.4.0
+var butaneVersions = map[string]string{
+	"3.2.0": "4.8.0",
+	"3.4.0": "4.14.0",
+}
+
+// ButaneVersion returns the openshift Butane variant version that renders a MachineConfig with
+// the given Ignition spec version
+func ButaneVersion(ignitionVersion string) (string, error) {
+	version, ok := butaneVersions[ignitionVersion]
+	if !ok {
+		return "", fmt.Errorf("no openshift Butane variant renders Ignition %s, use 3.2.0 or 3.4.0", ignitionVersion)
+	}
+	return version, nil
+}
+
+// Butane represents an openshift variant Butane config, which butane transpiles into a MachineConfig
+type Butane struct {
+	Variant  string        `yaml:"variant"`
+	Version  string        `yaml:"version"`
+	Metadata Metadata      `yaml:"metadata"`
+	Storage  ButaneStorage `yaml:"storage"`
+}
+
+type ButaneStorage struct {
+	Files []ButaneFile `yaml:"files"`
+}
+
+type ButaneFile struct {
+	Path      string         `yaml:"path"`
+	Mode      FileMode       `yaml:"mode"`
+	Overwrite bool           `yaml:"overwrite"`
+	Contents  ButaneContents `yaml:"contents"`
+}
+
+// ButaneContents holds file contents inline, so they stay readable and reviewable in Git
+type ButaneContents struct {
+	Inline string `yaml:"inline"`
+}
+
+// FileMode is a file permission written in octal, as in Butane examples
+type FileMode int
+
+func (m FileMode) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0" + strconv.FormatInt(int64(m), 8)}, nil
+}
+
+// NewButane converts a MachineConfig into a Butane config with the same name, labels and
+// files, decoding the data URLs of the files into inline contents. The variant version is
+// chosen so that butane renders the Ignition spec version of the MachineConfig.
+func NewButane(mc *MachineConfig) (*Butane, error) {
+	version, err := ButaneVersion(mc.Spec.Config.Ignition.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ButaneFile, 0, len(mc.Spec.Config.Storage.Files))
+	for i := range mc.Spec.Config.Storage.Files {
+		file := &mc.Spec.Config.Storage.Files[i]
+
+		content, err := DecodeDataURL(file.Contents.Source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+
+		files = append(files, ButaneFile{
+			Path:      file.Path,
+			Mode:      FileMode(file.Mode),
+			Overwrite: file.Overwrite,
+			Contents:  ButaneContents{Inline: content},
+		})
+	}
+
+	return &Butane{
+		Variant:  "openshift",
+		Version:  version,
+		Metadata: mc.Metadata,
+		Storage:  ButaneStorage{Files: files},
+	}, nil
+}
+
+// MarshalButane renders a MachineConfig as an openshift variant Butane config
+func MarshalButane(mc *MachineConfig) ([]byte, error) {
+	butane, err := NewButane(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(butane)
+}
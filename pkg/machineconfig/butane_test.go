@@ -0,0 +1,97 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalButane(t *testing.T) {
+	mc, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{
+		{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+
+	data, err := MarshalButane(mc)
+	if err != nil {
+		t.Fatalf("MarshalButane() error = %v", err)
+	}
+	output := string(data)
+
+	for _, expected := range []string{
+		"variant: openshift\n",
+		"version: 4.8.0\n",
+		"name: 50-interface-rename\n",
+		"machineconfiguration.openshift.io/role: worker\n",
+		"path: /etc/systemd/network/10-ptp0.link\n",
+		"mode: 0644\n",
+		"MACAddress=aa:bb:cc:dd:ee:01\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if strings.Contains(output, "source: data:") {
+		t.Errorf("Expected inline contents instead of a data URL, got:\n%s", output)
+	}
+
+	// The octal mode and literal contents must read back as the original values
+	var decoded struct {
+		Storage struct {
+			Files []struct {
+				Mode     int `yaml:"mode"`
+				Contents struct {
+					Inline string `yaml:"inline"`
+				} `yaml:"contents"`
+			} `yaml:"files"`
+		} `yaml:"storage"`
+	}
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	if len(decoded.Storage.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(decoded.Storage.Files))
+	}
+	if decoded.Storage.Files[0].Mode != DefaultFileMode {
+		t.Errorf("Expected mode %d, got %d", DefaultFileMode, decoded.Storage.Files[0].Mode)
+	}
+	if decoded.Storage.Files[0].Contents.Inline != generateLinkFileWithName("aa:bb:cc:dd:ee:01", "ptp0") {
+		t.Errorf("Unexpected inline contents: %q", decoded.Storage.Files[0].Contents.Inline)
+	}
+}
+
+func TestButaneVersion(t *testing.T) {
+	tests := []struct {
+		ignitionVersion string
+		expected        string
+		expectError     bool
+	}{
+		{ignitionVersion: "3.2.0", expected: "4.8.0"},
+		{ignitionVersion: "3.4.0", expected: "4.14.0"},
+		{ignitionVersion: "3.3.0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ignitionVersion, func(t *testing.T) {
+			result, err := ButaneVersion(tt.ignitionVersion)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.ignitionVersion)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ButaneVersion() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
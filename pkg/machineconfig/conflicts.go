@@ -0,0 +1,173 @@
+package machineconfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LinkConflict is a .link file of another MachineConfig rolled out to the same pool that
+// interferes with a generated .link file. systemd applies only the first .link file, in
+// lexical order of the file names, whose [Match] section matches an interface.
+type LinkConflict struct {
+	Path               string
+	OtherMachineConfig string
+	OtherPath          string
+	// Blocking conflicts prevent the generated file from taking effect
+	Blocking bool
+	Message  string
+}
+
+// FindLinkConflicts compares the .link files of a MachineConfig with those of the other
+// MachineConfigs of its pool. It reports files written by both, files sorting earlier and
+// matching the same interfaces, which shadow the generated ones, and generated files that
+// shadow the settings of other files for the interfaces they both match.
+func FindLinkConflicts(mc *MachineConfig, others []*MachineConfig) ([]LinkConflict, error) {
+	files, err := parseLinkFiles(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []LinkConflict
+	for _, other := range others {
+		if other.Metadata.Name == mc.Metadata.Name {
+			continue
+		}
+		otherFiles, err := parseLinkFiles(other)
+		if err != nil {
+			return nil, fmt.Errorf("MachineConfig %s: %w", other.Metadata.Name, err)
+		}
+
+		for _, file := range files {
+			for _, otherFile := range otherFiles {
+				if conflict, found := linkConflict(file, otherFile); found {
+					conflict.OtherMachineConfig = other.Metadata.Name
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// parsedLinkFile is a .link file of a MachineConfig with its parsed contents
+type parsedLinkFile struct {
+	path string
+	link *LinkFile
+}
+
+func parseLinkFiles(mc *MachineConfig) ([]parsedLinkFile, error) {
+	var files []parsedLinkFile
+	for i := range mc.Spec.Config.Storage.Files {
+		file := &mc.Spec.Config.Storage.Files[i]
+		if !isLinkFile(file.Path) {
+			continue
+		}
+		link, err := parseLinkFileSource(file)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, parsedLinkFile{path: file.Path, link: link})
+	}
+	return files, nil
+}
+
+func linkConflict(file, other parsedLinkFile) (LinkConflict, bool) {
+	conflict := LinkConflict{Path: file.path, OtherPath: other.path}
+
+	if file.path == other.path {
+		conflict.Blocking = true
+		conflict.Message = "is also written by another MachineConfig, only the one whose name sorts last ends up on the nodes"
+		return conflict, true
+	}
+
+	if !matchesOverlap(&file.link.Match, &other.link.Match) || linkSettings(file.link) == linkSettings(other.link) {
+		return conflict, false
+	}
+
+	name, otherName := path.Base(file.path), path.Base(other.path)
+	if otherName < name {
+		conflict.Blocking = true
+		conflict.Message = fmt.Sprintf("matches the same interfaces as %s, which sorts before %s and is applied instead", otherName, name)
+	} else {
+		conflict.Message = fmt.Sprintf("matches the same interfaces as %s and sorts before it, so its settings (%s) no longer apply to them",
+			otherName, linkSettings(other.link))
+	}
+	return conflict, true
+}
+
+// linkSettings returns the settings of the [Link] section on one line
+func linkSettings(link *LinkFile) string {
+	rendered := (&LinkFile{Link: link.Link}).Render()
+	_, settings, _ := strings.Cut(rendered, "[Link]\n")
+	return strings.Join(strings.Fields(settings), " ")
+}
+
+// matchesOverlap reports whether the interfaces matched by two [Match] sections overlap. Only conditions
+// set in both sections can be compared: a MAC address and a driver may or may not select the same
+// interface, so sections without a condition in common are not considered overlapping, unless
+// one of them has none of these conditions. Such a section is empty, or only has conditions such
+// as OriginalName=* or Type=ether, and may match every interface.
+func matchesOverlap(a, b *LinkMatch) bool {
+	if !hasComparableConditions(a) || !hasComparableConditions(b) {
+		return true
+	}
+
+	compared := false
+	conditions := [][2]string{
+		{strings.ToLower(a.MACAddress), strings.ToLower(b.MACAddress)},
+		{strings.ToLower(a.PermanentMACAddress), strings.ToLower(b.PermanentMACAddress)},
+		{a.Path, b.Path},
+		{a.Driver, b.Driver},
+	}
+	for _, property := range a.Property {
+		key, value, _ := strings.Cut(property, "=")
+		for _, otherProperty := range b.Property {
+			if otherKey, otherValue, _ := strings.Cut(otherProperty, "="); key == otherKey {
+				conditions = append(conditions, [2]string{value, otherValue})
+			}
+		}
+	}
+
+	for _, condition := range conditions {
+		if condition[0] == "" || condition[1] == "" {
+			continue
+		}
+		if !patternsOverlap(condition[0], condition[1]) {
+			return false
+		}
+		compared = true
+	}
+
+	return compared
+}
+
+// hasComparableConditions reports whether a [Match] section has a condition matchesOverlap compares
+func hasComparableConditions(match *LinkMatch) bool {
+	return match.MACAddress != "" || match.PermanentMACAddress != "" || match.Path != "" || match.Driver != "" || len(match.Property) > 0
+}
+
+// patternsOverlap reports whether two whitespace-separated lists of glob patterns may match a common value
+func patternsOverlap(a, b string) bool {
+	for _, pattern := range strings.Fields(a) {
+		for _, otherPattern := range strings.Fields(b) {
+			if globsOverlap(pattern, otherPattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func globsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	matched, _ := path.Match(a, b)
+	if matched {
+		return true
+	}
+	matched, _ = path.Match(b, a)
+	return matched
+}
@@ -0,0 +1,120 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindLinkConflicts(t *testing.T) {
+	port := 1
+	mc, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{
+		{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"},
+		{Driver: "ice", Port: &port, PortKey: PortKeyFunction, Name: "ptp1"},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		path             string
+		content          string
+		expectedBlocking []bool
+		errContains      string
+	}{
+		{
+			name:             "Same path",
+			path:             "/etc/systemd/network/10-ptp0.link",
+			content:          generateLinkFileWithName("aa:bb:cc:dd:ee:01", "ptp0"),
+			expectedBlocking: []bool{true},
+		},
+		{
+			name:             "Earlier file matching the same MAC",
+			path:             "/etc/systemd/network/05-sriov.link",
+			content:          "[Match]\nMACAddress=AA:BB:CC:DD:EE:01\n\n[Link]\nNamePolicy=path\n",
+			expectedBlocking: []bool{true},
+		},
+		{
+			name:             "Later file matching every ice port",
+			path:             "/etc/systemd/network/99-ice.link",
+			content:          "[Match]\nDriver=ic*\n\n[Link]\nNamePolicy=path\nMTUBytes=9000\n",
+			expectedBlocking: []bool{false},
+		},
+		{
+			name:             "Earlier file matching every interface",
+			path:             "/etc/systemd/network/00-all.link",
+			content:          "[Match]\n\n[Link]\nNamePolicy=mac\n",
+			expectedBlocking: []bool{true, true},
+		},
+		{
+			name:             "Earlier file matching every original name",
+			path:             "/etc/systemd/network/05-vendor.link",
+			content:          "[Match]\nOriginalName=*\n\n[Link]\nNamePolicy=onboard\n",
+			expectedBlocking: []bool{true, true},
+		},
+		{
+			name:             "Later file matching every Ethernet interface",
+			path:             "/etc/systemd/network/99-ether.link",
+			content:          "[Match]\nType=ether\n\n[Link]\nMTUBytes=9000\n",
+			expectedBlocking: []bool{false, false},
+		},
+		{
+			name:    "Different kinds of conditions",
+			path:    "/etc/systemd/network/05-i40e.link",
+			content: "[Match]\nDriver=i40e\n\n[Link]\nName=lan0\n",
+		},
+		{
+			name:    "Other MAC address",
+			path:    "/etc/systemd/network/05-mgmt.link",
+			content: generateLinkFileWithName("aa:bb:cc:dd:ee:02", "mgmt0"),
+		},
+		{
+			name:    "Other driver",
+			path:    "/etc/systemd/network/05-mlx.link",
+			content: "[Match]\nDriver=mlx5_core\nProperty=ID_NET_NAME_PATH=*f1\n\n[Link]\nName=mlx1\n",
+		},
+		{
+			name:    "Same settings",
+			path:    "/etc/systemd/network/05-ptp0.link",
+			content: generateLinkFileWithName("aa:bb:cc:dd:ee:01", "ptp0"),
+		},
+		{
+			name:        "Unreadable file",
+			path:        "/etc/systemd/network/05-broken.link",
+			content:     "Name=ptp0\n",
+			errContains: "MachineConfig 99-other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := createMachineConfig("99-other", "worker", []File{
+				{Path: tt.path, Contents: Contents{Source: encodeLinkFile(tt.content)}},
+				{Path: "/etc/chrony.conf", Contents: Contents{Source: "data:,server%20ntp"}},
+			})
+
+			conflicts, err := FindLinkConflicts(mc, []*MachineConfig{mc, other})
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindLinkConflicts() error = %v", err)
+			}
+
+			if len(conflicts) != len(tt.expectedBlocking) {
+				t.Fatalf("Expected %d conflicts, got %+v", len(tt.expectedBlocking), conflicts)
+			}
+			for i, conflict := range conflicts {
+				if conflict.Blocking != tt.expectedBlocking[i] {
+					t.Errorf("Expected conflict %d blocking=%v, got %+v", i, tt.expectedBlocking[i], conflict)
+				}
+				if conflict.OtherMachineConfig != "99-other" || conflict.OtherPath != tt.path {
+					t.Errorf("Unexpected conflicting file in %+v", conflict)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package machineconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NodeRoleLabelPrefix is the prefix of the node label selecting the nodes of a MachineConfigPool
+const NodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// basePoolNames are the pools every cluster has, which the nodes of custom pools stay selected by
+var basePoolNames = []string{"master", "worker"}
+
+// poolSelectorFields are the spec fields of a custom MachineConfigPool set by this tool
+var poolSelectorFields = []string{"machineConfigSelector", "nodeSelector"}
+
+// MachineConfigPool represents a custom OpenShift MachineConfigPool resource
+type MachineConfigPool struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   Metadata              `yaml:"metadata"`
+	Spec       MachineConfigPoolSpec `yaml:"spec"`
+}
+
+type MachineConfigPoolSpec struct {
+	MachineConfigSelector LabelSelector `yaml:"machineConfigSelector"`
+	NodeSelector          LabelSelector `yaml:"nodeSelector"`
+}
+
+type LabelSelector struct {
+	MatchLabels      map[string]string          `yaml:"matchLabels,omitempty" json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `yaml:"matchExpressions,omitempty" json:"matchExpressions,omitempty"`
+}
+
+type LabelSelectorRequirement struct {
+	Key      string   `yaml:"key" json:"key"`
+	Operator string   `yaml:"operator" json:"operator"`
+	Values   []string `yaml:"values" json:"values"`
+}
+
+// NewCustomMachineConfigPool creates a pool for the nodes labelled with its node role that renders
+// the MachineConfigs of both the base role and its own, so its nodes keep the base configuration
+func NewCustomMachineConfigPool(name, baseRole string) *MachineConfigPool {
+	return &MachineConfigPool{
+		APIVersion: "machineconfiguration.openshift.io/v1",
+		Kind:       "MachineConfigPool",
+		Metadata: Metadata{
+			Name:   name,
+			Labels: map[string]string{ManagedByLabel: ManagedByValue},
+		},
+		Spec: MachineConfigPoolSpec{
+			MachineConfigSelector: LabelSelector{
+				MatchExpressions: []LabelSelectorRequirement{{
+					Key:      RoleLabel,
+					Operator: "In",
+					Values:   []string{baseRole, name},
+				}},
+			},
+			NodeSelector: LabelSelector{
+				MatchLabels: map[string]string{NodeRoleLabelPrefix + name: ""},
+			},
+		},
+	}
+}
+
+// HardwarePoolName derives a stable pool name from the base role and a description of the
+// hardware of a group of nodes, so the same hardware always maps to the same pool
+func HardwarePoolName(baseRole string, signature []string) string {
+	sorted := append([]string(nil), signature...)
+	sort.Strings(sorted)
+
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.Join(sorted, "\n")))
+
+	return fmt.Sprintf("%s-%08x", baseRole, hash.Sum32())
+}
+
+func MarshalMachineConfigPool(pool *MachineConfigPool) ([]byte, error) {
+	return yaml.Marshal(pool)
+}
+
+// ApplyMachineConfigPool creates a MachineConfigPool, or updates the selectors of an existing one.
+// A pool not labelled as managed by this tool is only accepted when its selectors already match.
+func ApplyMachineConfigPool(ctx context.Context, kubeconfigPath string, pool *MachineConfigPool) error {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	obj, err := poolToUnstructured(pool)
+	if err != nil {
+		return err
+	}
+
+	existing, err := dynamicClient.Resource(machineConfigPoolGVR).Get(ctx, pool.Metadata.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := dynamicClient.Resource(machineConfigPoolGVR).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create MachineConfigPool: %w", err)
+		}
+		fmt.Printf("Created new MachineConfigPool: %s\n", pool.Metadata.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get MachineConfigPool %s: %w", pool.Metadata.Name, err)
+	}
+
+	diff, err := poolSelectorDiff(existing, obj)
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		fmt.Printf("MachineConfigPool %s is up to date\n", pool.Metadata.Name)
+		return nil
+	}
+	if existing.GetLabels()[ManagedByLabel] != ManagedByValue {
+		return fmt.Errorf("MachineConfigPool %s exists without the %s=%s label, refusing to change its selectors:\n  %s",
+			pool.Metadata.Name, ManagedByLabel, ManagedByValue, strings.Join(diff, "\n  "))
+	}
+	fmt.Printf("Updating MachineConfigPool %s:\n  %s\n", pool.Metadata.Name, strings.Join(diff, "\n  "))
+
+	// Keep the status and any other spec fields managed by the Machine Config Operator
+	for _, field := range poolSelectorFields {
+		value, _, _ := unstructured.NestedMap(obj.Object, "spec", field)
+		if err := unstructured.SetNestedMap(existing.Object, value, "spec", field); err != nil {
+			return fmt.Errorf("failed to update MachineConfigPool %s: %w", pool.Metadata.Name, err)
+		}
+	}
+
+	if _, err := dynamicClient.Resource(machineConfigPoolGVR).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update MachineConfigPool: %w", err)
+	}
+	fmt.Printf("Updated existing MachineConfigPool: %s\n", pool.Metadata.Name)
+	return nil
+}
+
+// LabelNodeForPool labels a node with the node role selecting a custom pool. A node can only be in
+// one custom pool: the labels selecting it into other custom pools managed by this tool are removed,
+// and a node selected by a custom pool this tool does not manage is refused.
+func LabelNodeForPool(ctx context.Context, kubeconfigPath, nodeName, pool string) error {
+	clientset, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	pools, err := dynamicClient.Resource(machineConfigPoolGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list MachineConfigPools: %w", err)
+	}
+
+	stale, err := otherCustomPoolLabels(node.Labels, pools.Items, pool)
+	if err != nil {
+		return fmt.Errorf("node %s: %w", nodeName, err)
+	}
+
+	label := NodeRoleLabelPrefix + pool
+	patchLabels := map[string]interface{}{label: ""}
+	for _, staleLabel := range stale {
+		// A null value removes the label in a merge patch
+		patchLabels[staleLabel] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": patchLabels},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build node patch: %w", err)
+	}
+
+	if _, err := clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to label node %s: %w", nodeName, err)
+	}
+
+	fmt.Printf("Labelled node %s with %s\n", nodeName, label)
+	for _, staleLabel := range stale {
+		fmt.Printf("Removed label %s from node %s\n", staleLabel, nodeName)
+	}
+	return nil
+}
+
+// otherCustomPoolLabels returns the node labels selecting a node into custom pools other than pool.
+// Only the matchLabels of pools managed by this tool are returned; a node selected by another
+// custom pool, or by a managed pool through matchExpressions, is an error.
+func otherCustomPoolLabels(nodeLabels map[string]string, pools []unstructured.Unstructured, pool string) ([]string, error) {
+	var stale []string
+	for i := range pools {
+		name := pools[i].GetName()
+		if name == pool || slices.Contains(basePoolNames, name) {
+			continue
+		}
+
+		status, err := poolStatusFromUnstructured(&pools[i])
+		if err != nil {
+			return nil, err
+		}
+		if status.NodeSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(status.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector in MachineConfigPool %s: %w", name, err)
+		}
+		if !selector.Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+
+		if pools[i].GetLabels()[ManagedByLabel] != ManagedByValue || len(status.NodeSelector.MatchExpressions) > 0 {
+			return nil, fmt.Errorf("it is already in MachineConfigPool %s, remove it from that pool first", name)
+		}
+		for key := range status.NodeSelector.MatchLabels {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// poolSelectorDiff describes each selector of an existing pool that differs from the wanted one
+func poolSelectorDiff(existing, wanted *unstructured.Unstructured) ([]string, error) {
+	var diff []string
+	for _, field := range poolSelectorFields {
+		current, _, _ := unstructured.NestedMap(existing.Object, "spec", field)
+		value, _, _ := unstructured.NestedMap(wanted.Object, "spec", field)
+
+		currentJSON, err := json.Marshal(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", field, err)
+		}
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", field, err)
+		}
+		if string(currentJSON) != string(valueJSON) {
+			diff = append(diff, fmt.Sprintf("%s: %s, want %s", field, currentJSON, valueJSON))
+		}
+	}
+	return diff, nil
+}
+
+func poolToUnstructured(pool *MachineConfigPool) (*unstructured.Unstructured, error) {
+	selectors := []*LabelSelector{&pool.Spec.MachineConfigSelector, &pool.Spec.NodeSelector}
+
+	spec := map[string]interface{}{}
+	for i, field := range poolSelectorFields {
+		data, err := json.Marshal(selectors[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", field, err)
+		}
+		var value map[string]interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", field, err)
+		}
+		spec[field] = value
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": pool.APIVersion,
+			"kind":       pool.Kind,
+			"metadata": map[string]interface{}{
+				"name": pool.Metadata.Name,
+			},
+			"spec": spec,
+		},
+	}
+	obj.SetLabels(pool.Metadata.Labels)
+	return obj, nil
+}
@@ -0,0 +1,146 @@
+package machineconfig
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewCustomMachineConfigPool(t *testing.T) {
+	pool := NewCustomMachineConfigPool("worker-ptp", "worker")
+
+	if pool.Metadata.Name != "worker-ptp" {
+		t.Errorf("Expected name worker-ptp, got %s", pool.Metadata.Name)
+	}
+
+	expressions := pool.Spec.MachineConfigSelector.MatchExpressions
+	if len(expressions) != 1 || expressions[0].Key != RoleLabel ||
+		strings.Join(expressions[0].Values, ",") != "worker,worker-ptp" {
+		t.Errorf("Expected the pool to render worker and worker-ptp MachineConfigs, got %+v", expressions)
+	}
+
+	if value, ok := pool.Spec.NodeSelector.MatchLabels["node-role.kubernetes.io/worker-ptp"]; !ok || value != "" {
+		t.Errorf("Expected node selector on node-role.kubernetes.io/worker-ptp, got %v", pool.Spec.NodeSelector.MatchLabels)
+	}
+
+	obj, err := poolToUnstructured(pool)
+	if err != nil {
+		t.Fatalf("poolToUnstructured() error = %v", err)
+	}
+	if obj.GetLabels()[ManagedByLabel] != ManagedByValue {
+		t.Errorf("Expected %s label, got %v", ManagedByLabel, obj.GetLabels())
+	}
+	status, err := poolStatusFromUnstructured(obj)
+	if err != nil {
+		t.Fatalf("poolStatusFromUnstructured() error = %v", err)
+	}
+	if status.NodeSelector == nil || len(status.NodeSelector.MatchLabels) != 1 {
+		t.Errorf("Expected node selector to round-trip, got %+v", status.NodeSelector)
+	}
+}
+
+func TestHardwarePoolName(t *testing.T) {
+	name := HardwarePoolName("worker", []string{"ptp0/ice/0x8086/0x1593/pci-0000:3b:00.0", "ptp1/ice/0x8086/0x1593/pci-0000:3b:00.1"})
+
+	if !strings.HasPrefix(name, "worker-") || len(name) != len("worker-")+8 {
+		t.Errorf("Expected worker-<8 hex digits>, got %s", name)
+	}
+
+	reordered := HardwarePoolName("worker", []string{"ptp1/ice/0x8086/0x1593/pci-0000:3b:00.1", "ptp0/ice/0x8086/0x1593/pci-0000:3b:00.0"})
+	if reordered != name {
+		t.Errorf("Expected the name not to depend on interface order, got %s and %s", name, reordered)
+	}
+
+	if other := HardwarePoolName("worker", []string{"ptp0/igb/0x8086/0x1521/pci-0000:01:00.0"}); other == name {
+		t.Errorf("Expected different hardware to get a different pool, got %s for both", name)
+	}
+}
+
+func TestPoolSelectorDiff(t *testing.T) {
+	wanted, err := poolToUnstructured(NewCustomMachineConfigPool("worker-ptp", "worker"))
+	if err != nil {
+		t.Fatalf("poolToUnstructured() error = %v", err)
+	}
+
+	same := wanted.DeepCopy()
+	if diff, err := poolSelectorDiff(same, wanted); err != nil || len(diff) != 0 {
+		t.Errorf("Expected no difference for identical selectors, got %v, %v", diff, err)
+	}
+
+	changed := wanted.DeepCopy()
+	if err := unstructured.SetNestedStringMap(changed.Object, map[string]string{"node-role.kubernetes.io/ptp": ""}, "spec", "nodeSelector", "matchLabels"); err != nil {
+		t.Fatalf("SetNestedStringMap() error = %v", err)
+	}
+	diff, err := poolSelectorDiff(changed, wanted)
+	if err != nil {
+		t.Fatalf("poolSelectorDiff() error = %v", err)
+	}
+	expected := `nodeSelector: {"matchLabels":{"node-role.kubernetes.io/ptp":""}}, want {"matchLabels":{"node-role.kubernetes.io/worker-ptp":""}}`
+	if len(diff) != 1 || diff[0] != expected {
+		t.Errorf("Expected %q, got %v", expected, diff)
+	}
+}
+
+func TestOtherCustomPoolLabels(t *testing.T) {
+	var pools []unstructured.Unstructured
+	for _, pool := range []*MachineConfigPool{
+		NewCustomMachineConfigPool("worker", "worker"),
+		NewCustomMachineConfigPool("worker-old", "worker"),
+		NewCustomMachineConfigPool("worker-new", "worker"),
+		NewCustomMachineConfigPool("infra", "worker"),
+	} {
+		obj, err := poolToUnstructured(pool)
+		if err != nil {
+			t.Fatalf("poolToUnstructured() error = %v", err)
+		}
+		pools = append(pools, *obj)
+	}
+	// The infra pool was created by the administrator
+	pools[3].SetLabels(nil)
+
+	tests := []struct {
+		name        string
+		nodeLabels  map[string]string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:       "Node in no custom pool",
+			nodeLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+		},
+		{
+			name:       "Node already in the pool",
+			nodeLabels: map[string]string{"node-role.kubernetes.io/worker": "", "node-role.kubernetes.io/worker-new": ""},
+		},
+		{
+			name:       "Node in an earlier pool of this tool",
+			nodeLabels: map[string]string{"node-role.kubernetes.io/worker": "", "node-role.kubernetes.io/worker-old": ""},
+			expected:   []string{"node-role.kubernetes.io/worker-old"},
+		},
+		{
+			name:        "Node in a pool of the administrator",
+			nodeLabels:  map[string]string{"node-role.kubernetes.io/worker": "", "node-role.kubernetes.io/infra": ""},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stale, err := otherCustomPoolLabels(tt.nodeLabels, pools, "worker-new")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", stale)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("otherCustomPoolLabels() error = %v", err)
+			}
+			if !slices.Equal(stale, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, stale)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package machineconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LinkSection is a section of a systemd .link file, such as [Match] or [Link], with its
+// settings in file order. Keys may repeat, as Property= does.
+type LinkSection struct {
+	Name    string      `json:"name"`
+	Entries []LinkEntry `json:"entries"`
+}
+
+type LinkEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DecodedLinkFile is a .link file of a MachineConfig with its contents parsed into sections
+type DecodedLinkFile struct {
+	Path     string        `json:"path"`
+	Sections []LinkSection `json:"sections"`
+}
+
+// DecodeDataURL decodes the contents of an Ignition data URL, handling both
+// URL-encoded (data:text/plain,...) and base64 (data:;base64,...) sources
+func DecodeDataURL(source string) (string, error) {
+	if !strings.HasPrefix(source, "data:") {
+		return "", fmt.Errorf("unsupported contents source %q: only data URLs are supported", truncate(source))
+	}
+
+	header, data, found := strings.Cut(strings.TrimPrefix(source, "data:"), ",")
+	if !found {
+		return "", fmt.Errorf("invalid data URL %q: missing ','", truncate(source))
+	}
+
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64 data URL: %w", err)
+		}
+		return string(decoded), nil
+	}
+
+	// PathUnescape keeps '+' as is, encodeLinkFile writes spaces as %20
+	decoded, err := url.PathUnescape(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode data URL: %w", err)
+	}
+	return decoded, nil
+}
+
+// LinkFilePaths returns the paths of the systemd .link files in a MachineConfig, sorted
+func LinkFilePaths(mc *MachineConfig) []string {
+	var paths []string
+	for _, file := range mc.Spec.Config.Storage.Files {
+		if isLinkFile(file.Path) {
+			paths = append(paths, file.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// LinkFiles returns the decoded contents of every systemd .link file in a MachineConfig, keyed by path
+func LinkFiles(mc *MachineConfig) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, file := range mc.Spec.Config.Storage.Files {
+		if !isLinkFile(file.Path) {
+			continue
+		}
+		content, err := DecodeDataURL(file.Contents.Source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		result[file.Path] = content
+	}
+	return result, nil
+}
+
+// DecodeLinkFiles decodes and parses every systemd .link file in a MachineConfig, sorted by path
+func DecodeLinkFiles(mc *MachineConfig) ([]DecodedLinkFile, error) {
+	files, err := LinkFiles(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	decoded := make([]DecodedLinkFile, 0, len(paths))
+	for _, path := range paths {
+		sections, err := ParseLinkSections(files[path])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		decoded = append(decoded, DecodedLinkFile{Path: path, Sections: sections})
+	}
+	return decoded, nil
+}
+
+// ParseLinkSections parses the sections of a systemd .link file, skipping blank and comment lines
+func ParseLinkSections(content string) ([]LinkSection, error) {
+	var sections []LinkSection
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			sections = append(sections, LinkSection{Name: strings.TrimSpace(line[1 : len(line)-1])})
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", i+1, line)
+		}
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("line %d: %s is outside of any section", i+1, strings.TrimSpace(key))
+		}
+
+		section := &sections[len(sections)-1]
+		section.Entries = append(section.Entries, LinkEntry{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return sections, nil
+}
+
+// machineConfigDocument is a YAML document holding either a MachineConfig or a list of them
+type machineConfigDocument struct {
+	MachineConfig `yaml:",inline"`
+	Items         []*MachineConfig `yaml:"items"`
+}
+
+// UnmarshalMachineConfigs reads the MachineConfigs of a YAML or JSON stream, such as the output of
+// this tool or of oc get machineconfigs -o yaml. Documents of other kinds are skipped.
+func UnmarshalMachineConfigs(data []byte) ([]*MachineConfig, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var configs []*MachineConfig
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MachineConfig: %w", err)
+		}
+
+		// The document is also decoded generically to find the fields MachineConfig does not keep
+		var document machineConfigDocument
+		var raw map[string]interface{}
+		if err := node.Decode(&document); err != nil {
+			return nil, fmt.Errorf("failed to parse MachineConfig: %w", err)
+		}
+		if err := node.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse MachineConfig: %w", err)
+		}
+
+		if document.Kind == machineConfigKind {
+			document.Unmodelled = unmodelledFields(raw)
+			configs = append(configs, &document.MachineConfig)
+		}
+		rawItems, _ := raw["items"].([]interface{})
+		for i, item := range document.Items {
+			if item.Kind == machineConfigKind {
+				rawItem, _ := rawItems[i].(map[string]interface{})
+				item.Unmodelled = unmodelledFields(rawItem)
+				configs = append(configs, item)
+			}
+		}
+	}
+	return configs, nil
+}
+
+func isLinkFile(path string) bool {
+	return strings.HasPrefix(path, "/etc/systemd/network/") && strings.HasSuffix(path, ".link")
+}
+
+func truncate(value string) string {
+	const maxLength = 40
+	if len(value) > maxLength {
+		return value[:maxLength] + "..."
+	}
+	return value
+}
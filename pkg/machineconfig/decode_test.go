@@ -0,0 +1,208 @@
+package machineconfig
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeDataURL(t *testing.T) {
+	content := "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp0\n"
+
+	tests := []struct {
+		name        string
+		source      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "URL-encoded as generated",
+			source:   encodeLinkFile(content),
+			expected: content,
+		},
+		{
+			name:     "Base64",
+			source:   "data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(content)),
+			expected: content,
+		},
+		{
+			name:     "Space and plus",
+			source:   "data:,NamePolicy=slot%20path+x",
+			expected: "NamePolicy=slot path+x",
+		},
+		{
+			name:        "Not a data URL",
+			source:      "https://example.com/10-ptp0.link",
+			expectError: true,
+		},
+		{
+			name:        "Missing comma",
+			source:      "data:text/plain",
+			expectError: true,
+		},
+		{
+			name:        "Invalid base64",
+			source:      "data:;base64,!!!",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeDataURL(tt.source)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecodeDataURL() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLinkFiles(t *testing.T) {
+	mc, err := NewMachineConfigWithExplicitNames("test-mc", "worker", []string{"aa:bb:cc:dd:ee:ff"}, []string{"ptp0"})
+	if err != nil {
+		t.Fatalf("Failed to create MachineConfig: %v", err)
+	}
+	mc.Spec.Config.Storage.Files = append(mc.Spec.Config.Storage.Files, File{
+		Path:     "/etc/chrony.conf",
+		Contents: Contents{Source: "data:,server%20ntp"},
+	})
+
+	files, err := LinkFiles(mc)
+	if err != nil {
+		t.Fatalf("LinkFiles() error = %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 link file, got %d", len(files))
+	}
+	if files["/etc/systemd/network/10-ptp0.link"] != generateLinkFileWithName("aa:bb:cc:dd:ee:ff", "ptp0") {
+		t.Errorf("Unexpected content: %q", files["/etc/systemd/network/10-ptp0.link"])
+	}
+}
+
+func TestLinkFilePaths(t *testing.T) {
+	mc, err := NewMachineConfigWithExplicitNames("test-mc", "worker", []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"}, []string{"ptp1", "ptp0"})
+	if err != nil {
+		t.Fatalf("Failed to create MachineConfig: %v", err)
+	}
+	mc.Spec.Config.Storage.Files = append(mc.Spec.Config.Storage.Files, File{
+		Path:     "/etc/chrony.conf",
+		Contents: Contents{Source: "data:,server%20ntp"},
+	})
+
+	paths := LinkFilePaths(mc)
+	expected := []string{"/etc/systemd/network/10-ptp0.link", "/etc/systemd/network/10-ptp1.link"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestParseLinkSections(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    []LinkSection
+		errContains string
+	}{
+		{
+			name:    "Repeated keys",
+			content: generateLinkFileWithPropertyAndName("8086", "1593", "ptp0"),
+			expected: []LinkSection{
+				{Name: "Match", Entries: []LinkEntry{{Key: "Property", Value: "ID_VENDOR_ID=0x8086"}, {Key: "Property", Value: "ID_MODEL_ID=0x1593"}}},
+				{Name: "Link", Entries: []LinkEntry{{Key: "Name", Value: "ptp0"}}},
+			},
+		},
+		{
+			name:    "Comments and spacing",
+			content: "# Generated\n[Match]\n; legacy\nMACAddress = aa:bb:cc:dd:ee:ff\n\n[Link]\nNamePolicy=slot path\n",
+			expected: []LinkSection{
+				{Name: "Match", Entries: []LinkEntry{{Key: "MACAddress", Value: "aa:bb:cc:dd:ee:ff"}}},
+				{Name: "Link", Entries: []LinkEntry{{Key: "NamePolicy", Value: "slot path"}}},
+			},
+		},
+		{
+			name:        "Entry outside of a section",
+			content:     "Name=ptp0\n",
+			errContains: "outside of any section",
+		},
+		{
+			name:        "Line without a value",
+			content:     "[Link]\nName\n",
+			errContains: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := ParseLinkSections(tt.content)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLinkSections() error = %v", err)
+			}
+			if !reflect.DeepEqual(sections, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, sections)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMachineConfigs(t *testing.T) {
+	mc, err := NewMachineConfigWithExplicitNames("test-mc", "worker", []string{"aa:bb:cc:dd:ee:ff"}, []string{"ptp0"})
+	if err != nil {
+		t.Fatalf("Failed to create MachineConfig: %v", err)
+	}
+	data, err := MarshalMachineConfig(mc)
+	if err != nil {
+		t.Fatalf("MarshalMachineConfig() error = %v", err)
+	}
+
+	pool, err := MarshalMachineConfigPool(NewCustomMachineConfigPool("worker-ptp", "worker"))
+	if err != nil {
+		t.Fatalf("MarshalMachineConfigPool() error = %v", err)
+	}
+
+	configs, err := UnmarshalMachineConfigs([]byte(string(pool) + "---\n" + string(data)))
+	if err != nil {
+		t.Fatalf("UnmarshalMachineConfigs() error = %v", err)
+	}
+	if len(configs) != 1 || configs[0].Metadata.Name != "test-mc" {
+		t.Fatalf("Expected only MachineConfig test-mc, got %+v", configs)
+	}
+
+	list := "apiVersion: v1\nkind: List\nitems:\n- apiVersion: machineconfiguration.openshift.io/v1\n  kind: MachineConfig\n  metadata:\n    name: listed-mc\n"
+	listed, err := UnmarshalMachineConfigs([]byte(list))
+	if err != nil {
+		t.Fatalf("UnmarshalMachineConfigs() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].Metadata.Name != "listed-mc" {
+		t.Errorf("Expected MachineConfig listed-mc from the list, got %+v", listed)
+	}
+
+	files, err := DecodeLinkFiles(configs[0])
+	if err != nil {
+		t.Fatalf("DecodeLinkFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "/etc/systemd/network/10-ptp0.link" {
+		t.Fatalf("Expected the ptp0 link file, got %+v", files)
+	}
+	if name := files[0].Sections[1].Entries[0]; name != (LinkEntry{Key: "Name", Value: "ptp0"}) {
+		t.Errorf("Expected Name=ptp0, got %+v", name)
+	}
+}
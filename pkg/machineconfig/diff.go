@@ -0,0 +1,219 @@
+package machineconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change, as in diff -u
+const diffContextLines = 3
+
+// FileDiff is the difference of a single file between two MachineConfigs
+type FileDiff struct {
+	Path string
+	// Unified holds the unified diff, empty when the file is identical
+	Unified string
+}
+
+// SpecUnchanged reports whether applying generated over existing leaves its spec as it is, so the pools
+// selecting it render no new config. The Ignition version and every file are compared, in order, and
+// fields of existing not modelled by this tool count as changes since applying drops them.
+func SpecUnchanged(existing, generated *MachineConfig) bool {
+	if len(existing.Unmodelled) > 0 {
+		return false
+	}
+	return reflect.DeepEqual(toUnstructured(existing).Object["spec"], toUnstructured(generated).Object["spec"])
+}
+
+// DiffMachineConfigs compares the .link files of the MachineConfig found on the cluster with a generated one.
+// A nil existing MachineConfig is treated as empty, so every generated file shows up as added.
+func DiffMachineConfigs(existing, generated *MachineConfig) ([]FileDiff, error) {
+	oldFiles := map[string]string{}
+	if existing != nil {
+		var err error
+		if oldFiles, err = LinkFiles(existing); err != nil {
+			return nil, fmt.Errorf("failed to decode existing MachineConfig: %w", err)
+		}
+	}
+
+	newFiles, err := LinkFiles(generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode generated MachineConfig: %w", err)
+	}
+
+	paths := make([]string, 0, len(oldFiles)+len(newFiles))
+	for path := range oldFiles {
+		paths = append(paths, path)
+	}
+	for path := range newFiles {
+		if _, ok := oldFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]FileDiff, 0, len(paths))
+	for _, path := range paths {
+		oldContent, inOld := oldFiles[path]
+		newContent, inNew := newFiles[path]
+
+		oldName, newName := "a"+path, "b"+path
+		if !inOld {
+			oldName = "/dev/null"
+		}
+		if !inNew {
+			newName = "/dev/null"
+		}
+
+		diffs = append(diffs, FileDiff{
+			Path:    path,
+			Unified: UnifiedDiff(oldName, newName, oldContent, newContent),
+		})
+	}
+
+	return diffs, nil
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns a unified diff of two texts, or an empty string when they are equal
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		hunkStart := max(first-diffContextLines, start)
+		hunkEnd := first
+		for unchanged := 0; hunkEnd < len(ops) && unchanged <= 2*diffContextLines; hunkEnd++ {
+			if ops[hunkEnd].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		hunkEnd = trimTrailingContext(ops, hunkStart, hunkEnd)
+
+		writeHunk(&b, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return b.String()
+}
+
+// trimTrailingContext shortens a hunk so it ends with at most diffContextLines unchanged lines
+func trimTrailingContext(ops []diffOp, start, end int) int {
+	unchanged := 0
+	for i := end - 1; i >= start && ops[i].kind == ' '; i-- {
+		unchanged++
+	}
+	if unchanged > diffContextLines {
+		return end - unchanged + diffContextLines
+	}
+	return end
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp, start, end int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	// diff -u reports the line before an empty range
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range ops[start:end] {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		b.WriteByte('\n')
+	}
+}
+
+// diffLines computes a line-based edit script using the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,140 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name:     "Identical",
+			oldText:  "a\nb\n",
+			newText:  "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "Changed line",
+			oldText:  "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp0\n",
+			newText:  "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp1\n",
+			expected: "--- a\n+++ b\n@@ -2,4 +2,4 @@\n MACAddress=aa:bb:cc:dd:ee:ff\n \n [Link]\n-Name=ptp0\n+Name=ptp1\n",
+		},
+		{
+			name:     "Added file",
+			oldText:  "",
+			newText:  "x\ny\n",
+			expected: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name:     "Removed file",
+			oldText:  "x\n",
+			newText:  "",
+			expected: "--- a\n+++ b\n@@ -1,1 +0,0 @@\n-x\n",
+		},
+		{
+			name:     "Separate hunks",
+			oldText:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			newText:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UnifiedDiff("a", "b", tt.oldText, tt.newText)
+			if result != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDiffMachineConfigs(t *testing.T) {
+	existing, err := NewMachineConfigWithExplicitNames("test-mc", "worker",
+		[]string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"}, []string{"ptp0", "ptp1"})
+	if err != nil {
+		t.Fatalf("Failed to create MachineConfig: %v", err)
+	}
+
+	generated, err := NewMachineConfigWithExplicitNames("test-mc", "worker",
+		[]string{"aa:bb:cc:dd:ee:ff", "32:44:55:66:77:88"}, []string{"ptp0", "ptp2"})
+	if err != nil {
+		t.Fatalf("Failed to create MachineConfig: %v", err)
+	}
+
+	diffs, err := DiffMachineConfigs(existing, generated)
+	if err != nil {
+		t.Fatalf("DiffMachineConfigs() error = %v", err)
+	}
+
+	changed := map[string]string{}
+	for _, diff := range diffs {
+		if diff.Unified != "" {
+			changed[diff.Path] = diff.Unified
+		}
+	}
+
+	if len(diffs) != 3 || len(changed) != 2 {
+		t.Fatalf("Expected 3 files with 2 changes, got %d files with %d changes", len(diffs), len(changed))
+	}
+	if !strings.Contains(changed["/etc/systemd/network/10-ptp1.link"], "+++ /dev/null") {
+		t.Errorf("Expected ptp1 to be removed:\n%s", changed["/etc/systemd/network/10-ptp1.link"])
+	}
+	if !strings.Contains(changed["/etc/systemd/network/10-ptp2.link"], "--- /dev/null") {
+		t.Errorf("Expected ptp2 to be added:\n%s", changed["/etc/systemd/network/10-ptp2.link"])
+	}
+
+	// A missing MachineConfig shows every generated file as added
+	diffs, err = DiffMachineConfigs(nil, generated)
+	if err != nil {
+		t.Fatalf("DiffMachineConfigs() error = %v", err)
+	}
+	for _, diff := range diffs {
+		if !strings.HasPrefix(diff.Unified, "--- /dev/null") {
+			t.Errorf("Expected %s to be added:\n%s", diff.Path, diff.Unified)
+		}
+	}
+}
+
+func TestSpecUnchanged(t *testing.T) {
+	newConfig := func() *MachineConfig {
+		mc, err := NewMachineConfigWithExplicitNames("test-mc", "worker", []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"}, []string{"ptp0", "ptp1"})
+		if err != nil {
+			t.Fatalf("Failed to create MachineConfig: %v", err)
+		}
+		return mc
+	}
+
+	tests := []struct {
+		name      string
+		change    func(mc *MachineConfig)
+		unchanged bool
+	}{
+		{name: "Identical", change: func(mc *MachineConfig) {}, unchanged: true},
+		{name: "Comment only", change: func(mc *MachineConfig) { mc.Spec.Config.Storage.Files[0].Comment = "" }, unchanged: true},
+		{name: "Ignition version", change: func(mc *MachineConfig) { mc.Spec.Config.Ignition.Version = "3.4.0" }},
+		{name: "File mode", change: func(mc *MachineConfig) { mc.Spec.Config.Storage.Files[0].Mode = 0o600 }},
+		{name: "Overwrite", change: func(mc *MachineConfig) { mc.Spec.Config.Storage.Files[1].Overwrite = false }},
+		{name: "File order", change: func(mc *MachineConfig) {
+			files := mc.Spec.Config.Storage.Files
+			files[0], files[1] = files[1], files[0]
+		}},
+		{name: "Unmodelled fields", change: func(mc *MachineConfig) { mc.Unmodelled = []string{"spec.kernelArguments"} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := newConfig()
+			tt.change(existing)
+
+			if got := SpecUnchanged(existing, newConfig()); got != tt.unchanged {
+				t.Errorf("Expected SpecUnchanged() = %v, got %v", tt.unchanged, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package machineconfig
+
+import "fmt"
+
+// NewMachineConfigWithDriverAndName creates a MachineConfig matching interfaces by kernel driver (systemd Driver=)
+// with an explicit name. Vendor and model IDs are optional and, when given, are ANDed with the driver match.
+func NewMachineConfigWithDriverAndName(name, role, driver, vendorID, modelID, interfaceName string) (*MachineConfig, error) {
+	if driver == "" {
+		return nil, fmt.Errorf("driver must be specified")
+	}
+
+	rules := []Rule{{Driver: driver, VendorID: vendorID, ModelID: modelID, Name: interfaceName}}
+	return NewMachineConfigFromRules(name, role, rules)
+}
+
+// NewMachineConfigWithDriverAndPolicy creates a MachineConfig matching interfaces by kernel driver (systemd Driver=)
+// with NamePolicy. Vendor and model IDs are optional and, when given, are ANDed with the driver match.
+func NewMachineConfigWithDriverAndPolicy(name, role, driver, vendorID, modelID, namePolicy string) (*MachineConfig, error) {
+	if driver == "" {
+		return nil, fmt.Errorf("driver must be specified")
+	}
+
+	rules := []Rule{{Driver: driver, VendorID: vendorID, ModelID: modelID, NamePolicy: namePolicy}}
+	return NewMachineConfigFromRules(name, role, rules)
+}
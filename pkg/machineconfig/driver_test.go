@@ -0,0 +1,79 @@
+package machineconfig
+
+import "testing"
+
+func TestNewMachineConfigWithDriverAndName(t *testing.T) {
+	tests := []struct {
+		name            string
+		driver          string
+		vendorID        string
+		modelID         string
+		expectedPath    string
+		expectedContent string
+		expectError     bool
+	}{
+		{
+			name:            "Driver only",
+			driver:          "igc",
+			expectedPath:    "/etc/systemd/network/10-ptp0.link",
+			expectedContent: "[Match]\nDriver=igc\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:            "Driver with vendor and model",
+			driver:          "ice",
+			vendorID:        "0x8086",
+			modelID:         "1593",
+			expectedPath:    "/etc/systemd/network/10-ptp0.link",
+			expectedContent: "[Match]\nDriver=ice\nProperty=ID_VENDOR_ID=0x8086\nProperty=ID_MODEL_ID=0x1593\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:        "Missing driver",
+			vendorID:    "0x8086",
+			modelID:     "0x1593",
+			expectError: true,
+		},
+		{
+			name:        "Vendor without model",
+			driver:      "ice",
+			vendorID:    "0x8086",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, err := NewMachineConfigWithDriverAndName("test-mc", "worker", tt.driver, tt.vendorID, tt.modelID, "ptp0")
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewMachineConfigWithDriverAndName() error = %v", err)
+			}
+
+			file := mc.Spec.Config.Storage.Files[0]
+			if file.Path != tt.expectedPath {
+				t.Errorf("Expected path %s, got %s", tt.expectedPath, file.Path)
+			}
+			if file.Comment != tt.expectedContent {
+				t.Errorf("Expected content:\n%s\ngot:\n%s", tt.expectedContent, file.Comment)
+			}
+		})
+	}
+}
+
+func TestNewMachineConfigWithDriverAndPolicy(t *testing.T) {
+	mc, err := NewMachineConfigWithDriverAndPolicy("test-mc", "worker", "ice", "0x8086", "0x1593", "path")
+	if err != nil {
+		t.Fatalf("NewMachineConfigWithDriverAndPolicy() error = %v", err)
+	}
+
+	expectedPath := "/etc/systemd/network/10-interface-ice-8086-1593.link"
+	if mc.Spec.Config.Storage.Files[0].Path != expectedPath {
+		t.Errorf("Expected path %s, got %s", expectedPath, mc.Spec.Config.Storage.Files[0].Path)
+	}
+}
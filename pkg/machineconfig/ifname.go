@@ -0,0 +1,48 @@
+package machineconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxInterfaceNameLength is the longest interface name the kernel accepts, IFNAMSIZ minus the
+// terminating NUL byte
+const MaxInterfaceNameLength = 15
+
+// kernelNamePattern matches the names the kernel gives interfaces before udev renames them.
+// Renaming an interface to such a name races with the kernel naming another interface.
+var kernelNamePattern = regexp.MustCompile(`^(eth|wlan|wwan)[0-9]+$`)
+
+// ValidateInterfaceName checks a name against the rules of the kernel and of systemd for
+// interface names: at most 15 bytes of printable ASCII, without whitespace, '/', ':' or '%',
+// not "." or "..", and not only digits, which would be mistaken for an interface index.
+func ValidateInterfaceName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("interface name must not be empty")
+	case len(name) > MaxInterfaceNameLength:
+		return fmt.Errorf("interface name %q is %d characters long, the kernel allows at most %d", name, len(name), MaxInterfaceNameLength)
+	case name == "." || name == "..":
+		return fmt.Errorf("interface name %q is reserved", name)
+	case strings.Trim(name, "0123456789") == "":
+		return fmt.Errorf("interface name %q must not be only digits", name)
+	}
+
+	for _, c := range name {
+		if c > unicode.MaxASCII || !unicode.IsPrint(c) || unicode.IsSpace(c) || strings.ContainsRune("/:%", c) {
+			return fmt.Errorf("interface name %q contains invalid character %q", name, c)
+		}
+	}
+
+	return nil
+}
+
+// InterfaceNameWarning explains why a valid name may not be applied reliably, or returns an empty string
+func InterfaceNameWarning(name string) string {
+	if kernelNamePattern.MatchString(name) {
+		return fmt.Sprintf("interface name %s follows the kernel's own naming scheme, the rename can race with the kernel naming another interface %s", name, name)
+	}
+	return ""
+}
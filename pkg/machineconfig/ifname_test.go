@@ -0,0 +1,56 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInterfaceName(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifName      string
+		errContains string
+	}{
+		{name: "Simple", ifName: "ptp0"},
+		{name: "Punctuation", ifName: "ens1f0.100-a_b"},
+		{name: "Fifteen characters", ifName: "abcdefghijklmno"},
+		{name: "Kernel name", ifName: "eth0"},
+		{name: "Empty", ifName: "", errContains: "must not be empty"},
+		{name: "Sixteen characters", ifName: "abcdefghijklmnop", errContains: "at most 15"},
+		{name: "Slash", ifName: "ptp/0", errContains: "invalid character '/'"},
+		{name: "Colon", ifName: "ptp:0", errContains: "invalid character ':'"},
+		{name: "Percent", ifName: "ptp%d", errContains: "invalid character '%'"},
+		{name: "Whitespace", ifName: "ptp 0", errContains: "invalid character ' '"},
+		{name: "Non-ASCII", ifName: "ptpé", errContains: "invalid character"},
+		{name: "Dot", ifName: ".", errContains: "reserved"},
+		{name: "Only digits", ifName: "42", errContains: "only digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInterfaceName(tt.ifName)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestInterfaceNameWarning(t *testing.T) {
+	for _, name := range []string{"eth0", "wlan1", "wwan0"} {
+		if InterfaceNameWarning(name) == "" {
+			t.Errorf("Expected a warning for %s", name)
+		}
+	}
+	for _, name := range []string{"ptp0", "ethernet0", "eth-ptp", "eth"} {
+		if warning := InterfaceNameWarning(name); warning != "" {
+			t.Errorf("Unexpected warning for %s: %s", name, warning)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package machineconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SupportedIgnitionVersions lists the Ignition spec versions configs can be generated for, oldest first.
+// They are the versions the Machine Config Operator renders: 3.2.0 up to OpenShift 4.13, 3.4.0 from 4.14.
+var SupportedIgnitionVersions = []string{"3.2.0", "3.4.0"}
+
+// maxFileMode is the largest file mode Ignition accepts, permission and special bits included
+const maxFileMode = 0o7777
+
+// ignitionFileFeature is a file setting only available from a given Ignition spec version. Generated
+// files never use them, but files kept from an existing MachineConfig by a merge may.
+type ignitionFileFeature struct {
+	name       string
+	minVersion string
+	used       func(file *File) bool
+}
+
+var ignitionFileFeatures = []ignitionFileFeature{
+	{
+		name:       "special mode bits (setuid, setgid, sticky)",
+		minVersion: "3.4.0",
+		used:       func(file *File) bool { return file.Mode&^0o777 != 0 },
+	},
+}
+
+// ValidateIgnitionVersion checks that configs can be generated for an Ignition spec version
+func ValidateIgnitionVersion(version string) error {
+	if !slices.Contains(SupportedIgnitionVersions, version) {
+		return fmt.Errorf("unsupported Ignition version %q: must be one of %s", version, strings.Join(SupportedIgnitionVersions, ", "))
+	}
+	return nil
+}
+
+// CompareIgnitionVersions returns a negative number when a is older than b, zero when they are
+// equal and a positive number when a is newer. Both versions must be supported.
+func CompareIgnitionVersions(a, b string) int {
+	return slices.Index(SupportedIgnitionVersions, a) - slices.Index(SupportedIgnitionVersions, b)
+}
+
+// ValidateIgnitionConfig checks that every file of an Ignition config is valid and can be expressed
+// in its spec version
+func ValidateIgnitionConfig(config *Config) error {
+	version := config.Ignition.Version
+	if err := ValidateIgnitionVersion(version); err != nil {
+		return err
+	}
+
+	for i := range config.Storage.Files {
+		file := &config.Storage.Files[i]
+		if err := validateIgnitionFile(file); err != nil {
+			return err
+		}
+		for _, feature := range ignitionFileFeatures {
+			if feature.used(file) && CompareIgnitionVersions(version, feature.minVersion) < 0 {
+				return fmt.Errorf("%s: %s require Ignition %s or newer, got %s", file.Path, feature.name, feature.minVersion, version)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateIgnitionFile checks the rules every Ignition 3 spec version applies to files
+func validateIgnitionFile(file *File) error {
+	if file.Mode < 0 || file.Mode > maxFileMode {
+		return fmt.Errorf("%s: invalid mode %#o", file.Path, file.Mode)
+	}
+	if file.Overwrite && file.Contents.Source == "" {
+		return fmt.Errorf("%s: overwrite requires contents.source", file.Path)
+	}
+	return nil
+}
+
+// RenderedIgnitionVersion returns the Ignition version of the rendered config of a MachineConfigPool,
+// which is the newest version its Machine Config Operator supports. It is empty when the pool
+// has not rendered a config yet.
+func RenderedIgnitionVersion(ctx context.Context, kubeconfigPath, pool string) (string, error) {
+	status, err := GetPoolStatus(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return "", err
+	}
+	if status.RenderedConfig == "" {
+		return "", nil
+	}
+
+	rendered, err := GetMachineConfig(ctx, kubeconfigPath, status.RenderedConfig)
+	if err != nil {
+		return "", err
+	}
+	if rendered == nil {
+		return "", nil
+	}
+
+	return rendered.Spec.Config.Ignition.Version, nil
+}
+
+// MarshalIgnition renders the Ignition config of a MachineConfig as a standalone Ignition JSON
+// document, to be merged into install-time Ignition configs
+func MarshalIgnition(mc *MachineConfig) ([]byte, error) {
+	return json.MarshalIndent(mc.Spec.Config, "", "  ")
+}
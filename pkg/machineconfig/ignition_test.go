@@ -0,0 +1,148 @@
+package machineconfig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMarshalIgnition(t *testing.T) {
+	mc, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{
+		{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+	mc.Spec.Config.Ignition.Version = "3.4.0"
+
+	data, err := MarshalIgnition(mc)
+	if err != nil {
+		t.Fatalf("MarshalIgnition() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got error %v:\n%s", err, data)
+	}
+
+	if version := decoded["ignition"].(map[string]interface{})["version"]; version != "3.4.0" {
+		t.Errorf("Expected ignition version 3.4.0, got %v", version)
+	}
+
+	files := decoded["storage"].(map[string]interface{})["files"].([]interface{})
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	file := files[0].(map[string]interface{})
+	if file["path"] != "/etc/systemd/network/10-ptp0.link" {
+		t.Errorf("Unexpected path %v", file["path"])
+	}
+	if file["mode"] != float64(DefaultFileMode) {
+		t.Errorf("Expected mode %d, got %v", DefaultFileMode, file["mode"])
+	}
+	if _, ok := file["Comment"]; ok {
+		t.Error("Expected the link file comment not to be serialized")
+	}
+	if file["contents"].(map[string]interface{})["source"] != mc.Spec.Config.Storage.Files[0].Contents.Source {
+		t.Errorf("Unexpected contents %v", file["contents"])
+	}
+}
+
+func TestValidateIgnitionConfig(t *testing.T) {
+	linkFile := File{Path: "/etc/systemd/network/10-ptp0.link", Mode: DefaultFileMode, Overwrite: true, Contents: Contents{Source: "data:,"}}
+	setuidFile := linkFile
+	setuidFile.Mode = 0o4755
+	emptyFile := linkFile
+	emptyFile.Contents.Source = ""
+	invalidModeFile := linkFile
+	invalidModeFile.Mode = 0o100644
+
+	tests := []struct {
+		name        string
+		version     string
+		file        File
+		errContains string
+	}{
+		{name: "Oldest version", version: "3.2.0", file: linkFile},
+		{name: "Newest version", version: "3.4.0", file: linkFile},
+		{name: "Not rendered by the MCO", version: "3.3.0", file: linkFile, errContains: "unsupported Ignition version"},
+		{name: "Ignition 3.0", version: "3.0.0", file: linkFile, errContains: "unsupported Ignition version"},
+		{name: "Ignition 2", version: "2.2.0", file: linkFile, errContains: "unsupported Ignition version"},
+		{name: "Unknown version", version: "3.5.0", file: linkFile, errContains: "unsupported Ignition version"},
+		{name: "Special mode bits", version: "3.4.0", file: setuidFile},
+		{name: "Special mode bits too old", version: "3.2.0", file: setuidFile, errContains: "require Ignition 3.4.0"},
+		{name: "Invalid mode", version: "3.4.0", file: invalidModeFile, errContains: "invalid mode 0100644"},
+		{name: "Overwrite without source", version: "3.2.0", file: emptyFile, errContains: "overwrite requires contents.source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Ignition: Ignition{Version: tt.version}, Storage: Storage{Files: []File{tt.file}}}
+			err := ValidateIgnitionConfig(&config)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestCompareIgnitionVersions(t *testing.T) {
+	if CompareIgnitionVersions("3.2.0", "3.4.0") >= 0 {
+		t.Error("Expected 3.2.0 to be older than 3.4.0")
+	}
+	if CompareIgnitionVersions("3.4.0", "3.2.0") <= 0 {
+		t.Error("Expected 3.4.0 to be newer than 3.2.0")
+	}
+	if CompareIgnitionVersions("3.4.0", "3.4.0") != 0 {
+		t.Error("Expected 3.4.0 to equal itself")
+	}
+}
+
+func TestValidateIgnitionConfigMergedFiles(t *testing.T) {
+	// A hand-written MachineConfig declaring Ignition 3.2.0 with a setuid helper
+	existing := `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 50-interface-rename
+spec:
+  config:
+    ignition:
+      version: 3.2.0
+    storage:
+      files:
+      - path: /usr/local/bin/ptp-helper
+        mode: 2541
+        overwrite: true
+        contents:
+          source: data:,
+`
+	configs, err := UnmarshalMachineConfigs([]byte(existing))
+	if err != nil {
+		t.Fatalf("UnmarshalMachineConfigs() error = %v", err)
+	}
+	generated, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"}})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+
+	merged, err := MergeMachineConfigs(configs[0], generated)
+	if err != nil {
+		t.Fatalf("MergeMachineConfigs() error = %v", err)
+	}
+
+	err = ValidateIgnitionConfig(&merged.Spec.Config)
+	if err == nil || !strings.Contains(err.Error(), "/usr/local/bin/ptp-helper: special mode bits (setuid, setgid, sticky) require Ignition 3.4.0 or newer, got 3.2.0") {
+		t.Errorf("Expected the setuid file to be rejected for Ignition 3.2.0, got %v", err)
+	}
+
+	merged.Spec.Config.Ignition.Version = "3.4.0"
+	if err := ValidateIgnitionConfig(&merged.Spec.Config); err != nil {
+		t.Errorf("Unexpected error for Ignition 3.4.0: %v", err)
+	}
+}
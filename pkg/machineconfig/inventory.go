@@ -0,0 +1,82 @@
+package machineconfig
+
+import (
+	"bufio"
+	"strings"
+)
+
+// InterfaceDumpScript prints the sysfs MAC address and the udev properties of every
+// physical network interface on a host, one blank-line separated block per interface.
+// It is run in the host root filesystem, e.g. through chroot /host on a debug pod.
+const InterfaceDumpScript = `for dev in /sys/class/net/*; do
+  [ -e "$dev/device" ] || continue
+  echo "INTERFACE_NAME=${dev##*/}"
+  echo "SYSFS_ADDRESS=$(cat "$dev/address")"
+  echo "PERMANENT_ADDRESS=$(ethtool -P "${dev##*/}" 2>/dev/null | sed -n 's/^Permanent address: //p')"
+  udevadm info -q property -p "$dev"
+  echo
+done`
+
+// zeroMACAddress is the permanent address ethtool reports for interfaces that have none
+const zeroMACAddress = "00:00:00:00:00:00"
+
+// NetworkInterface describes a network interface as reported by sysfs and udev on a node
+type NetworkInterface struct {
+	Name       string `json:"name" yaml:"name"`
+	MACAddress string `json:"mac" yaml:"mac"`
+	// PermanentMACAddress is the burned-in address reported by ethtool -P, which stays the same
+	// when the current address is changed, e.g. by bonding
+	PermanentMACAddress string `json:"permanentMac,omitempty" yaml:"permanentMac,omitempty"`
+	Driver              string `json:"driver,omitempty" yaml:"driver,omitempty"`
+	PCIPath             string `json:"pciPath,omitempty" yaml:"pciPath,omitempty"`
+	VendorID            string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	ModelID             string `json:"model,omitempty" yaml:"model,omitempty"`
+	// NamePath is the predictable path-based name udev computed (ID_NET_NAME_PATH), used to identify ports
+	NamePath string `json:"namePath,omitempty" yaml:"namePath,omitempty"`
+}
+
+// ParseInterfaceDump parses the output of InterfaceDumpScript. Lines that are not
+// KEY=value pairs, such as the messages printed by oc debug, are ignored.
+func ParseInterfaceDump(output string) []NetworkInterface {
+	var interfaces []NetworkInterface
+	var current *NetworkInterface
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+
+		if key == "INTERFACE_NAME" {
+			interfaces = append(interfaces, NetworkInterface{Name: value})
+			current = &interfaces[len(interfaces)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch key {
+		case "SYSFS_ADDRESS":
+			current.MACAddress = strings.ToLower(value)
+		case "PERMANENT_ADDRESS":
+			// Interfaces without a burned-in address, such as SR-IOV VFs, report all zeros
+			if value != zeroMACAddress {
+				current.PermanentMACAddress = strings.ToLower(value)
+			}
+		case "ID_NET_DRIVER":
+			current.Driver = value
+		case "ID_PATH":
+			current.PCIPath = value
+		case "ID_VENDOR_ID":
+			current.VendorID = ensureHexPrefix(value)
+		case "ID_MODEL_ID":
+			current.ModelID = ensureHexPrefix(value)
+		case "ID_NET_NAME_PATH":
+			current.NamePath = value
+		}
+	}
+
+	return interfaces
+}
@@ -0,0 +1,75 @@
+package machineconfig
+
+import "testing"
+
+const testInterfaceDump = `Starting pod/worker-0-debug-abcde ...
+To use host binaries, run ` + "`chroot /host`" + `
+INTERFACE_NAME=ptp0
+SYSFS_ADDRESS=B4:96:91:AA:BB:01
+PERMANENT_ADDRESS=B4:96:91:AA:BB:01
+DEVPATH=/devices/pci0000:3a/0000:3a:00.0/0000:3b:00.0/net/ptp0
+INTERFACE=ptp0
+ID_NET_DRIVER=ice
+ID_PATH=pci-0000:3b:00.0
+ID_VENDOR_ID=0x8086
+ID_MODEL_ID=0x1593
+ID_NET_NAME_PATH=enp59s0f0
+
+INTERFACE_NAME=eno1
+SYSFS_ADDRESS=3c:ec:ef:00:00:01
+PERMANENT_ADDRESS=
+ID_NET_DRIVER=igb
+ID_PATH=pci-0000:01:00.0
+ID_VENDOR_ID=8086
+ID_MODEL_ID=1521
+
+INTERFACE_NAME=ens1f0v0
+SYSFS_ADDRESS=b2:6e:1a:00:00:01
+PERMANENT_ADDRESS=00:00:00:00:00:00
+ID_NET_DRIVER=iavf
+
+Removing debug pod ...
+`
+
+func TestParseInterfaceDump(t *testing.T) {
+	interfaces := ParseInterfaceDump(testInterfaceDump)
+
+	if len(interfaces) != 3 {
+		t.Fatalf("Expected 3 interfaces, got %d", len(interfaces))
+	}
+
+	expected := NetworkInterface{
+		Name:                "ptp0",
+		MACAddress:          "b4:96:91:aa:bb:01",
+		PermanentMACAddress: "b4:96:91:aa:bb:01",
+		Driver:              "ice",
+		PCIPath:             "pci-0000:3b:00.0",
+		VendorID:            "0x8086",
+		ModelID:             "0x1593",
+		NamePath:            "enp59s0f0",
+	}
+	if interfaces[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, interfaces[0])
+	}
+
+	// ethtool prints nothing for interfaces without a permanent address
+	if interfaces[1].PermanentMACAddress != "" {
+		t.Errorf("Expected no permanent MAC, got %s", interfaces[1].PermanentMACAddress)
+	}
+
+	// SR-IOV VFs have no burned-in address and ethtool reports zeros
+	if interfaces[2].PermanentMACAddress != "" {
+		t.Errorf("Expected no permanent MAC for a VF, got %s", interfaces[2].PermanentMACAddress)
+	}
+
+	// IDs without the 0x prefix get it added
+	if interfaces[1].VendorID != "0x8086" || interfaces[1].ModelID != "0x1521" {
+		t.Errorf("Expected 0x prefixed IDs, got %s/%s", interfaces[1].VendorID, interfaces[1].ModelID)
+	}
+}
+
+func TestParseInterfaceDumpEmpty(t *testing.T) {
+	if interfaces := ParseInterfaceDump("error: no such node\n"); len(interfaces) != 0 {
+		t.Errorf("Expected no interfaces, got %+v", interfaces)
+	}
+}
@@ -3,14 +3,17 @@ package machineconfig
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -49,12 +52,21 @@ func IsClusterSingleNode(kubeconfigPath string) (useMasterRole bool, info string
 	return useMasterRole, info, nil
 }
 
-func getKubernetesClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+func getRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
 
+	return config, nil
+}
+
+func getKubernetesClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
@@ -63,6 +75,20 @@ func getKubernetesClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
+func getDynamicClient(kubeconfigPath string) (dynamic.Interface, error) {
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return dynamicClient, nil
+}
+
 func countNodeRoles(nodes []corev1.Node) nodeRoleCounts {
 	counts := nodeRoleCounts{}
 
@@ -121,16 +147,12 @@ func shouldUseMasterRole(totalNodes int, counts nodeRoleCounts) bool {
 	return totalNodes == 1 || (counts.schedulableMasters > 0 && counts.workerOnly == 0)
 }
 
-// ApplyMachineConfig applies a MachineConfig to the cluster
+// ApplyMachineConfig applies a MachineConfig to the cluster. An existing MachineConfig gets the
+// spec of mc and its labels, and keeps its other metadata, such as annotations.
 func ApplyMachineConfig(ctx context.Context, kubeconfigPath string, mc *MachineConfig) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-	if err != nil {
-		return fmt.Errorf("failed to build config: %w", err)
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %w", err)
+		return err
 	}
 
 	// Convert MachineConfig to unstructured
@@ -140,8 +162,8 @@ func ApplyMachineConfig(ctx context.Context, kubeconfigPath string, mc *MachineC
 	existing, err := dynamicClient.Resource(machineConfigGVR).Get(ctx, mc.Metadata.Name, metav1.GetOptions{})
 	if err == nil {
 		// Update existing
-		unstructuredMC.SetResourceVersion(existing.GetResourceVersion())
-		_, err = dynamicClient.Resource(machineConfigGVR).Update(ctx, unstructuredMC, metav1.UpdateOptions{})
+		updateMachineConfigObject(existing, mc)
+		_, err = dynamicClient.Resource(machineConfigGVR).Update(ctx, existing, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to update MachineConfig: %w", err)
 		}
@@ -158,6 +180,76 @@ func ApplyMachineConfig(ctx context.Context, kubeconfigPath string, mc *MachineC
 	return nil
 }
 
+// GetMachineConfig fetches a MachineConfig from the cluster by name.
+// It returns nil without an error when the MachineConfig does not exist.
+func GetMachineConfig(ctx context.Context, kubeconfigPath, name string) (*MachineConfig, error) {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := dynamicClient.Resource(machineConfigGVR).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MachineConfig %s: %w", name, err)
+	}
+
+	return fromUnstructured(obj)
+}
+
+// ListMachineConfigs lists the MachineConfigs on the cluster matching a label selector
+func ListMachineConfigs(ctx context.Context, kubeconfigPath, labelSelector string) ([]*MachineConfig, error) {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := dynamicClient.Resource(machineConfigGVR).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachineConfigs: %w", err)
+	}
+
+	result := make([]*MachineConfig, 0, len(list.Items))
+	for i := range list.Items {
+		mc, err := fromUnstructured(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, mc)
+	}
+
+	return result, nil
+}
+
+// DeleteMachineConfig deletes a MachineConfig from the cluster
+func DeleteMachineConfig(ctx context.Context, kubeconfigPath, name string) error {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	if err := dynamicClient.Resource(machineConfigGVR).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete MachineConfig %s: %w", name, err)
+	}
+
+	fmt.Printf("Deleted MachineConfig: %s\n", name)
+	return nil
+}
+
+// updateMachineConfigObject replaces the spec of an existing MachineConfig object with the one of
+// a MachineConfig and adds its labels, keeping the rest of the existing metadata
+func updateMachineConfigObject(existing *unstructured.Unstructured, mc *MachineConfig) {
+	labels := existing.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, len(mc.Metadata.Labels))
+	}
+	maps.Copy(labels, mc.Metadata.Labels)
+	existing.SetLabels(labels)
+	existing.Object["spec"] = toUnstructured(mc).Object["spec"]
+}
+
 func toUnstructured(mc *MachineConfig) *unstructured.Unstructured {
 	obj := &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -196,3 +288,71 @@ func convertFiles(files []File) []interface{} {
 	}
 	return result
 }
+
+func fromUnstructured(obj *unstructured.Unstructured) (*MachineConfig, error) {
+	version, _, err := unstructured.NestedString(obj.Object, "spec", "config", "ignition", "version")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignition version in MachineConfig %s: %w", obj.GetName(), err)
+	}
+
+	rawFiles, _, err := unstructured.NestedSlice(obj.Object, "spec", "config", "storage", "files")
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage files in MachineConfig %s: %w", obj.GetName(), err)
+	}
+
+	files := make([]File, 0, len(rawFiles))
+	for _, rawFile := range rawFiles {
+		fileMap, ok := rawFile.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid storage file entry in MachineConfig %s", obj.GetName())
+		}
+
+		path, _, _ := unstructured.NestedString(fileMap, "path")
+		mode, _, _ := unstructured.NestedFieldNoCopy(fileMap, "mode")
+		overwrite, _, _ := unstructured.NestedBool(fileMap, "overwrite")
+		source, _, _ := unstructured.NestedString(fileMap, "contents", "source")
+
+		files = append(files, File{
+			Path:      path,
+			Mode:      toInt(mode),
+			Overwrite: overwrite,
+			Contents: Contents{
+				Source: source,
+			},
+		})
+	}
+
+	return &MachineConfig{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Metadata: Metadata{
+			Name:   obj.GetName(),
+			Labels: obj.GetLabels(),
+		},
+		Spec: MachineConfigSpec{
+			Config: Config{
+				Ignition: Ignition{
+					Version: version,
+				},
+				Storage: Storage{
+					Files: files,
+				},
+			},
+		},
+		Unmodelled: unmodelledFields(obj.Object),
+	}, nil
+}
+
+// toInt converts a JSON number decoded by the dynamic client into an int
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
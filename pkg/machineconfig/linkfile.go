@@ -0,0 +1,161 @@
+package machineconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LinkFile is a systemd .link file. Render writes it in the layout of the generated MachineConfigs
+// and ParseLinkFile reads one back, keeping settings without a typed field in Other.
+type LinkFile struct {
+	Match LinkMatch
+	Link  LinkSettings
+	// Other holds the sections other than [Match] and [Link], such as [SR-IOV]
+	Other []LinkSection
+}
+
+// LinkMatch is the [Match] section of a .link file. The conditions that are set are ANDed together.
+type LinkMatch struct {
+	// MACAddress is a whitespace-separated list of MAC addresses, any of which matches
+	MACAddress string
+	Path       string
+	Driver     string
+	// PermanentMACAddress lists permanent addresses like MACAddress, which survive bonding and VF reconfiguration
+	PermanentMACAddress string
+	// Property lists udev property matches such as ID_VENDOR_ID=0x8086, one Property= line each
+	Property []string
+	Other    []LinkEntry
+}
+
+// LinkSettings is the [Link] section of a .link file
+type LinkSettings struct {
+	// NamePolicy lists the naming schemes tried in order, the first one yielding a name wins
+	NamePolicy []string
+	// Name is used when NamePolicy is empty or none of its schemes yields a name
+	Name  string
+	Other []LinkEntry
+}
+
+const (
+	linkSectionMatch = "Match"
+	linkSectionLink  = "Link"
+
+	linkKeyMACAddress          = "MACAddress"
+	linkKeyPermanentMACAddress = "PermanentMACAddress"
+	linkKeyPath                = "Path"
+	linkKeyDriver              = "Driver"
+	linkKeyProperty            = "Property"
+	linkKeyName                = "Name"
+	linkKeyNamePolicy          = "NamePolicy"
+)
+
+// Render returns the contents of the .link file
+func (f *LinkFile) Render() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[%s]\n", linkSectionMatch)
+	writeLinkEntry(&b, linkKeyMACAddress, f.Match.MACAddress)
+	writeLinkEntry(&b, linkKeyPermanentMACAddress, f.Match.PermanentMACAddress)
+	writeLinkEntry(&b, linkKeyPath, f.Match.Path)
+	writeLinkEntry(&b, linkKeyDriver, f.Match.Driver)
+	for _, property := range f.Match.Property {
+		writeLinkEntry(&b, linkKeyProperty, property)
+	}
+	writeLinkEntries(&b, f.Match.Other)
+
+	fmt.Fprintf(&b, "\n[%s]\n", linkSectionLink)
+	writeLinkEntry(&b, linkKeyNamePolicy, strings.Join(f.Link.NamePolicy, " "))
+	writeLinkEntry(&b, linkKeyName, f.Link.Name)
+	writeLinkEntries(&b, f.Link.Other)
+
+	for _, section := range f.Other {
+		fmt.Fprintf(&b, "\n[%s]\n", section.Name)
+		writeLinkEntries(&b, section.Entries)
+	}
+
+	return b.String()
+}
+
+func writeLinkEntry(b *strings.Builder, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s=%s\n", key, value)
+	}
+}
+
+func writeLinkEntries(b *strings.Builder, entries []LinkEntry) {
+	for _, entry := range entries {
+		fmt.Fprintf(b, "%s=%s\n", entry.Key, entry.Value)
+	}
+}
+
+// ParseLinkFile reads a .link file into a LinkFile. As in systemd, repeated MACAddress,
+// PermanentMACAddress, Path, Driver, Property and NamePolicy settings extend their lists, an
+// empty assignment such as Driver= resets the list, and a repeated Name overrides the previous one.
+func ParseLinkFile(content string) (*LinkFile, error) {
+	sections, err := ParseLinkSections(content)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &LinkFile{}
+	for _, section := range sections {
+		switch section.Name {
+		case linkSectionMatch:
+			for _, entry := range section.Entries {
+				file.Match.set(entry)
+			}
+		case linkSectionLink:
+			for _, entry := range section.Entries {
+				file.Link.set(entry)
+			}
+		default:
+			file.Other = append(file.Other, section)
+		}
+	}
+
+	return file, nil
+}
+
+func (m *LinkMatch) set(entry LinkEntry) {
+	switch entry.Key {
+	case linkKeyMACAddress:
+		m.MACAddress = joinFields(m.MACAddress, entry.Value)
+	case linkKeyPermanentMACAddress:
+		m.PermanentMACAddress = joinFields(m.PermanentMACAddress, entry.Value)
+	case linkKeyPath:
+		m.Path = joinFields(m.Path, entry.Value)
+	case linkKeyDriver:
+		m.Driver = joinFields(m.Driver, entry.Value)
+	case linkKeyProperty:
+		if entry.Value == "" {
+			m.Property = nil
+		} else {
+			m.Property = append(m.Property, entry.Value)
+		}
+	default:
+		m.Other = append(m.Other, entry)
+	}
+}
+
+func (s *LinkSettings) set(entry LinkEntry) {
+	switch entry.Key {
+	case linkKeyName:
+		s.Name = entry.Value
+	case linkKeyNamePolicy:
+		if entry.Value == "" {
+			s.NamePolicy = nil
+		} else {
+			s.NamePolicy = append(s.NamePolicy, strings.Fields(entry.Value)...)
+		}
+	default:
+		s.Other = append(s.Other, entry)
+	}
+}
+
+// joinFields extends a whitespace-separated list with the fields of value, or resets it when value is empty
+func joinFields(list, value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	return strings.Join(append(strings.Fields(list), strings.Fields(value)...), " ")
+}
@@ -0,0 +1,106 @@
+package machineconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkFileRender(t *testing.T) {
+	file := LinkFile{
+		Match: LinkMatch{
+			MACAddress: "aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02",
+			Driver:     "ice",
+			Property:   []string{"ID_VENDOR_ID=0x8086", "ID_MODEL_ID=0x1593"},
+		},
+		Link: LinkSettings{
+			NamePolicy: []string{"slot", "path"},
+			Other:      []LinkEntry{{Key: "MTUBytes", Value: "9000"}},
+		},
+	}
+
+	expected := `[Match]
+MACAddress=aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02
+Driver=ice
+Property=ID_VENDOR_ID=0x8086
+Property=ID_MODEL_ID=0x1593
+
+[Link]
+NamePolicy=slot path
+MTUBytes=9000
+`
+	if result := file.Render(); result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestParseLinkFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected LinkFile
+	}{
+		{
+			name:    "Generated file",
+			content: generateLinkFile(&Rule{PCIPath: "pci-0000:3b:00.0", VendorID: "8086", ModelID: "1593", Name: "ptp0"}),
+			expected: LinkFile{
+				Match: LinkMatch{Path: "pci-0000:3b:00.0", Property: []string{"ID_VENDOR_ID=0x8086", "ID_MODEL_ID=0x1593"}},
+				Link:  LinkSettings{Name: "ptp0"},
+			},
+		},
+		{
+			name:    "Repeated settings",
+			content: "[Match]\nMACAddress=aa:bb:cc:dd:ee:01\nMACAddress=aa:bb:cc:dd:ee:02\n\n[Link]\nNamePolicy=slot\nNamePolicy=path\nName=eth0\nName=ptp0\n",
+			expected: LinkFile{
+				Match: LinkMatch{MACAddress: "aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02"},
+				Link:  LinkSettings{Name: "ptp0", NamePolicy: []string{"slot", "path"}},
+			},
+		},
+		{
+			name: "Empty assignments reset lists",
+			content: "[Match]\nMACAddress=aa:bb:cc:dd:ee:01\nMACAddress=\nMACAddress=aa:bb:cc:dd:ee:02\nDriver=ice\nDriver=\n" +
+				"Property=ID_VENDOR_ID=0x8086\nProperty=\n\n[Link]\nNamePolicy=slot\nNamePolicy=\nName=ptp0\n",
+			expected: LinkFile{
+				Match: LinkMatch{MACAddress: "aa:bb:cc:dd:ee:02"},
+				Link:  LinkSettings{Name: "ptp0"},
+			},
+		},
+		{
+			name:    "Permanent MAC address",
+			content: "[Match]\nPermanentMACAddress=aa:bb:cc:dd:ee:01\nPermanentMACAddress=aa:bb:cc:dd:ee:02\n\n[Link]\nName=ptp0\n",
+			expected: LinkFile{
+				Match: LinkMatch{PermanentMACAddress: "aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02"},
+				Link:  LinkSettings{Name: "ptp0"},
+			},
+		},
+		{
+			name:    "Unknown settings and sections",
+			content: "[Match]\nOriginalName=ens*\n\n[Link]\nName=ptp0\nMTUBytes=9000\n\n[SR-IOV]\nVirtualFunction=0\n",
+			expected: LinkFile{
+				Match: LinkMatch{Other: []LinkEntry{{Key: "OriginalName", Value: "ens*"}}},
+				Link:  LinkSettings{Name: "ptp0", Other: []LinkEntry{{Key: "MTUBytes", Value: "9000"}}},
+				Other: []LinkSection{{Name: "SR-IOV", Entries: []LinkEntry{{Key: "VirtualFunction", Value: "0"}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseLinkFile(tt.content)
+			if err != nil {
+				t.Fatalf("ParseLinkFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(*file, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *file)
+			}
+
+			// Rendering the parsed file and parsing it again must give the same model
+			again, err := ParseLinkFile(file.Render())
+			if err != nil {
+				t.Fatalf("ParseLinkFile() of the rendered file error = %v", err)
+			}
+			if !reflect.DeepEqual(again, file) {
+				t.Errorf("Round trip changed %+v into %+v", file, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package machineconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// WriteLinkFiles writes the .link files of a MachineConfig into a directory, under the same file
+// names they get in /etc/systemd/network, and returns the paths written. The files can be copied
+// to hosts outside OpenShift or inspected with systemd-analyze.
+func WriteLinkFiles(mc *MachineConfig, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var written []string
+	for i := range mc.Spec.Config.Storage.Files {
+		file := &mc.Spec.Config.Storage.Files[i]
+		if !isLinkFile(file.Path) {
+			continue
+		}
+
+		content, err := DecodeDataURL(file.Contents.Source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+
+		target := filepath.Join(dir, path.Base(file.Path))
+		if err := os.WriteFile(target, []byte(content), os.FileMode(file.Mode)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, target)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,42 @@
+package machineconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteLinkFiles(t *testing.T) {
+	mc, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{
+		{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"},
+		{MACAddress: "aa:bb:cc:dd:ee:02", NamePolicy: "mac"},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "network")
+	written, err := WriteLinkFiles(mc, dir)
+	if err != nil {
+		t.Fatalf("WriteLinkFiles() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"10-ptp0.link":                   generateLinkFileWithName("aa:bb:cc:dd:ee:01", "ptp0"),
+		"10-interface-aabbccddee02.link": generateLinkFileWithPolicy("aa:bb:cc:dd:ee:02", "mac"),
+	}
+	if len(written) != len(expected) {
+		t.Fatalf("Expected %d files, got %v", len(expected), written)
+	}
+
+	for name, content := range expected {
+		target := filepath.Join(dir, name)
+		data, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", target, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s: expected %q, got %q", name, content, string(data))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package machineconfig
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ethernetAddressLength is the length in bytes of the MAC addresses of Ethernet interfaces
+const ethernetAddressLength = 6
+
+// NormalizeMACAddress parses a MAC address written with colons, dashes or in Cisco dotted
+// notation (aabb.ccdd.eeff) and returns it in lowercase colon form, as udev reports it.
+// Only unicast Ethernet addresses can identify an interface: zero and multicast addresses,
+// including broadcast, are rejected.
+func NormalizeMACAddress(mac string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q", mac)
+	}
+
+	switch {
+	case len(hw) != ethernetAddressLength:
+		return "", fmt.Errorf("invalid MAC address %q: not a 48-bit Ethernet address", mac)
+	case bytes.Equal(hw, make(net.HardwareAddr, ethernetAddressLength)):
+		return "", fmt.Errorf("invalid MAC address %q: the zero address does not identify an interface", mac)
+	case hw[0]&1 != 0:
+		return "", fmt.Errorf("invalid MAC address %q: multicast addresses do not identify an interface", mac)
+	}
+
+	return hw.String(), nil
+}
+
+// NormalizeMACAddressList normalizes a whitespace-separated list of MAC addresses, as in
+// MACAddress=, and rejects addresses listed twice
+func NormalizeMACAddressList(list string) (string, error) {
+	fields := strings.Fields(list)
+	normalized := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+
+	for _, field := range fields {
+		mac, err := NormalizeMACAddress(field)
+		if err != nil {
+			return "", err
+		}
+		if seen[mac] {
+			return "", fmt.Errorf("MAC address %s is listed twice", mac)
+		}
+		seen[mac] = true
+		normalized = append(normalized, mac)
+	}
+
+	return strings.Join(normalized, " "), nil
+}
+
+// MACMatch selects which address of an interface the MAC addresses of a rule are matched against.
+// Bonding and SR-IOV VF configuration change the current address, the permanent one stays the
+// burned-in address of the NIC.
+type MACMatch string
+
+const (
+	// MACMatchCurrent matches the current address with MACAddress=
+	MACMatchCurrent MACMatch = "current"
+	// MACMatchPermanent matches the permanent address with PermanentMACAddress=
+	MACMatchPermanent MACMatch = "permanent"
+	// MACMatchBoth writes both settings, so the current and the permanent address must be listed
+	MACMatchBoth MACMatch = "both"
+)
+
+// ParseMACMatch validates a MAC match, an empty value selects MACMatchCurrent
+func ParseMACMatch(value string) (MACMatch, error) {
+	switch MACMatch(strings.TrimSpace(value)) {
+	case "", MACMatchCurrent:
+		return MACMatchCurrent, nil
+	case MACMatchPermanent:
+		return MACMatchPermanent, nil
+	case MACMatchBoth:
+		return MACMatchBoth, nil
+	default:
+		return "", fmt.Errorf("invalid MAC match %q: must be %s, %s or %s", value, MACMatchCurrent, MACMatchPermanent, MACMatchBoth)
+	}
+}
+
+// matchesCurrent reports whether the current address is matched, which is the default
+func (m MACMatch) matchesCurrent() bool {
+	return m != MACMatchPermanent
+}
+
+// matchesPermanent reports whether the permanent address is matched
+func (m MACMatch) matchesPermanent() bool {
+	return m == MACMatchPermanent || m == MACMatchBoth
+}
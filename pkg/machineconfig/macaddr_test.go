@@ -0,0 +1,93 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeMACAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		errContains string
+	}{
+		{name: "Canonical", input: "aa:bb:cc:dd:ee:ff", expected: "aa:bb:cc:dd:ee:ff"},
+		{name: "Uppercase", input: "AA:BB:CC:DD:EE:FF", expected: "aa:bb:cc:dd:ee:ff"},
+		{name: "Dashes", input: "3C-EC-EF-00-00-01", expected: "3c:ec:ef:00:00:01"},
+		{name: "Cisco dotted", input: "3cec.ef00.0001", expected: "3c:ec:ef:00:00:01"},
+		{name: "Surrounding whitespace", input: " aa:bb:cc:dd:ee:ff\t", expected: "aa:bb:cc:dd:ee:ff"},
+		{name: "Typo", input: "aa:bb:cc:dd:ee:fg", errContains: "invalid MAC address"},
+		{name: "Too short", input: "aa:bb:cc:dd:ee", errContains: "invalid MAC address"},
+		{name: "EUI-64", input: "aa:bb:cc:dd:ee:ff:00:11", errContains: "not a 48-bit Ethernet address"},
+		{name: "Zero", input: "00:00:00:00:00:00", errContains: "zero address"},
+		{name: "Multicast", input: "01:00:5e:00:00:01", errContains: "multicast"},
+		{name: "Broadcast", input: "ff:ff:ff:ff:ff:ff", errContains: "multicast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizeMACAddress(tt.input)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeMACAddress() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalizeMACAddressList(t *testing.T) {
+	result, err := NormalizeMACAddressList("AA:BB:CC:DD:EE:01  aa-bb-cc-dd-ee-02")
+	if err != nil {
+		t.Fatalf("NormalizeMACAddressList() error = %v", err)
+	}
+	if result != "aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02" {
+		t.Errorf("Unexpected list %q", result)
+	}
+
+	if _, err := NormalizeMACAddressList("aa:bb:cc:dd:ee:01 AA:BB:CC:DD:EE:01"); err == nil || !strings.Contains(err.Error(), "listed twice") {
+		t.Errorf("Expected duplicate error, got %v", err)
+	}
+}
+
+func TestParseMACMatch(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    MACMatch
+		expectError bool
+	}{
+		{input: "", expected: MACMatchCurrent},
+		{input: "current", expected: MACMatchCurrent},
+		{input: "permanent", expected: MACMatchPermanent},
+		{input: "both", expected: MACMatchBoth},
+		{input: "burned-in", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseMACMatch(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseMACMatch() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
@@ -14,6 +14,9 @@ type MachineConfig struct {
 	Kind       string            `yaml:"kind"`
 	Metadata   Metadata          `yaml:"metadata"`
 	Spec       MachineConfigSpec `yaml:"spec"`
+	// Unmodelled lists the fields set in a MachineConfig read from the cluster or a file that
+	// this type does not keep, such as systemd units or compressed file contents
+	Unmodelled []string `yaml:"-" json:"-"`
 }
 
 type Metadata struct {
@@ -25,46 +28,71 @@ type MachineConfigSpec struct {
 	Config Config `yaml:"config"`
 }
 
+// Config is the Ignition config embedded in a MachineConfig. Its JSON form is a standalone
+// Ignition config, as used at install time.
 type Config struct {
-	Ignition Ignition `yaml:"ignition"`
-	Storage  Storage  `yaml:"storage"`
+	Ignition Ignition `yaml:"ignition" json:"ignition"`
+	Storage  Storage  `yaml:"storage" json:"storage"`
 }
 
 type Ignition struct {
-	Version string `yaml:"version"`
+	Version string `yaml:"version" json:"version"`
 }
 
 type Storage struct {
-	Files []File `yaml:"files"`
+	Files []File `yaml:"files" json:"files"`
 }
 
 type File struct {
-	Path      string   `yaml:"path"`
-	Mode      int      `yaml:"mode"`
-	Overwrite bool     `yaml:"overwrite"`
-	Contents  Contents `yaml:"contents"`
-	Comment   string   `yaml:"-"` // Not serialized to YAML, only used for generating comments
+	Path      string   `yaml:"path" json:"path"`
+	Mode      int      `yaml:"mode" json:"mode"`
+	Overwrite bool     `yaml:"overwrite" json:"overwrite"`
+	Contents  Contents `yaml:"contents" json:"contents"`
+	Comment   string   `yaml:"-" json:"-"` // Not serialized, only used for generating comments
 }
 
 type Contents struct {
-	Source string `yaml:"source"`
+	Source string `yaml:"source" json:"source"`
 }
 
+const (
+	// RoleLabel selects the MachineConfigPool a MachineConfig is rolled out to
+	RoleLabel = "machineconfiguration.openshift.io/role"
+	// ManagedByLabel marks MachineConfigs generated by this tool
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the ManagedByLabel value of MachineConfigs generated by this tool
+	ManagedByValue = "ocp-rename-interfaces"
+
+	machineConfigKind = "MachineConfig"
+)
+
 const (
 	// DefaultFileMode is the default permission mode for systemd .link files
 	DefaultFileMode = 0o644
 	// DefaultConfigFileMode is the default permission mode for output config files
 	DefaultConfigFileMode = 0o600
+	// DefaultDirMode is the permission mode of output directories
+	DefaultDirMode = 0o750
+	// DefaultIgnitionVersion is the Ignition spec version of generated MachineConfigs
+	DefaultIgnitionVersion = "3.2.0"
 )
 
 // NewMachineConfigWithNames creates a MachineConfig with explicit interface names using a prefix
 //
 // Deprecated: Use NewMachineConfigWithExplicitNames for more control
 func NewMachineConfigWithNames(name, role string, macAddresses []string, namePrefix string) (*MachineConfig, error) {
+	macAddresses, err := normalizeMACAddresses(macAddresses)
+	if err != nil {
+		return nil, err
+	}
+
 	files := make([]File, 0, len(macAddresses))
 
 	for i, mac := range macAddresses {
 		interfaceName := fmt.Sprintf("%s%d", namePrefix, i)
+		if err := ValidateInterfaceName(interfaceName); err != nil {
+			return nil, err
+		}
 		linkFile := generateLinkFileWithName(mac, interfaceName)
 		encodedContent := encodeLinkFile(linkFile)
 
@@ -88,11 +116,21 @@ func NewMachineConfigWithExplicitNames(name, role string, macAddresses, names []
 	if len(macAddresses) != len(names) {
 		return nil, fmt.Errorf("number of MAC addresses (%d) must match number of names (%d)", len(macAddresses), len(names))
 	}
+	macAddresses, err := normalizeMACAddresses(macAddresses)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate, ok := findDuplicate(names); ok {
+		return nil, fmt.Errorf("interface name %s is given more than once", duplicate)
+	}
 
 	files := make([]File, 0, len(macAddresses))
 
 	for i, mac := range macAddresses {
 		interfaceName := names[i]
+		if err := ValidateInterfaceName(interfaceName); err != nil {
+			return nil, err
+		}
 		linkFile := generateLinkFileWithName(mac, interfaceName)
 		encodedContent := encodeLinkFile(linkFile)
 
@@ -112,6 +150,15 @@ func NewMachineConfigWithExplicitNames(name, role string, macAddresses, names []
 
 // NewMachineConfigWithPolicy creates a MachineConfig with NamePolicy
 func NewMachineConfigWithPolicy(name, role string, macAddresses []string, namePolicy string) (*MachineConfig, error) {
+	namePolicy, err := NormalizeNamePolicy(namePolicy)
+	if err != nil {
+		return nil, err
+	}
+	macAddresses, err = normalizeMACAddresses(macAddresses)
+	if err != nil {
+		return nil, err
+	}
+
 	files := make([]File, 0, len(macAddresses))
 
 	for _, mac := range macAddresses {
@@ -136,6 +183,10 @@ func NewMachineConfigWithPolicy(name, role string, macAddresses []string, namePo
 
 // NewMachineConfigWithPropertyAndName creates a MachineConfig with Property-based matching and explicit name
 func NewMachineConfigWithPropertyAndName(name, role, vendorID, modelID, interfaceName string) (*MachineConfig, error) {
+	if err := ValidateInterfaceName(interfaceName); err != nil {
+		return nil, err
+	}
+
 	linkFile := generateLinkFileWithPropertyAndName(vendorID, modelID, interfaceName)
 	encodedContent := encodeLinkFile(linkFile)
 
@@ -156,6 +207,11 @@ func NewMachineConfigWithPropertyAndName(name, role, vendorID, modelID, interfac
 
 // NewMachineConfigWithPropertyAndPolicy creates a MachineConfig with Property-based matching and NamePolicy
 func NewMachineConfigWithPropertyAndPolicy(name, role, vendorID, modelID, namePolicy string) (*MachineConfig, error) {
+	namePolicy, err := NormalizeNamePolicy(namePolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	linkFile := generateLinkFileWithPropertyAndPolicy(vendorID, modelID, namePolicy)
 	encodedContent := encodeLinkFile(linkFile)
 
@@ -179,20 +235,50 @@ func NewMachineConfigWithPropertyAndPolicy(name, role, vendorID, modelID, namePo
 	return createMachineConfig(name, role, files), nil
 }
 
+// normalizeMACAddresses normalizes MAC addresses given one per interface as NormalizeMACAddress
+// does, and rejects an address given twice, since both entries would generate the same file or
+// rename the same interface twice
+func normalizeMACAddresses(macAddresses []string) ([]string, error) {
+	normalized := make([]string, len(macAddresses))
+	for i, mac := range macAddresses {
+		var err error
+		if normalized[i], err = NormalizeMACAddress(mac); err != nil {
+			return nil, err
+		}
+	}
+	if duplicate, ok := findDuplicate(normalized); ok {
+		return nil, fmt.Errorf("MAC address %s is given more than once", duplicate)
+	}
+	return normalized, nil
+}
+
+// findDuplicate returns the first value that appears earlier in values
+func findDuplicate(values []string) (string, bool) {
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if seen[value] {
+			return value, true
+		}
+		seen[value] = true
+	}
+	return "", false
+}
+
 func createMachineConfig(name, role string, files []File) *MachineConfig {
 	return &MachineConfig{
 		APIVersion: "machineconfiguration.openshift.io/v1",
-		Kind:       "MachineConfig",
+		Kind:       machineConfigKind,
 		Metadata: Metadata{
 			Name: name,
 			Labels: map[string]string{
-				"machineconfiguration.openshift.io/role": role,
+				RoleLabel:      role,
+				ManagedByLabel: ManagedByValue,
 			},
 		},
 		Spec: MachineConfigSpec{
 			Config: Config{
 				Ignition: Ignition{
-					Version: "3.2.0",
+					Version: DefaultIgnitionVersion,
 				},
 				Storage: Storage{
 					Files: files,
@@ -203,57 +289,19 @@ func createMachineConfig(name, role string, files []File) *MachineConfig {
 }
 
 func generateLinkFileWithName(macAddress, interfaceName string) string {
-	return fmt.Sprintf(`[Match]
-MACAddress=%s
-
-[Link]
-Name=%s
-`, macAddress, interfaceName)
+	return generateLinkFile(&Rule{MACAddress: macAddress, Name: interfaceName})
 }
 
 func generateLinkFileWithPolicy(macAddress, namePolicy string) string {
-	return fmt.Sprintf(`[Match]
-MACAddress=%s
-
-[Link]
-NamePolicy=%s
-`, macAddress, namePolicy)
+	return generateLinkFile(&Rule{MACAddress: macAddress, NamePolicy: namePolicy})
 }
 
 func generateLinkFileWithPropertyAndName(vendorID, modelID, interfaceName string) string {
-	// Ensure 0x prefix - udev properties include the 0x prefix
-	if !strings.HasPrefix(vendorID, "0x") {
-		vendorID = "0x" + vendorID
-	}
-	if !strings.HasPrefix(modelID, "0x") {
-		modelID = "0x" + modelID
-	}
-
-	return fmt.Sprintf(`[Match]
-Property=ID_VENDOR_ID=%s
-Property=ID_MODEL_ID=%s
-
-[Link]
-Name=%s
-`, vendorID, modelID, interfaceName)
+	return generateLinkFile(&Rule{VendorID: vendorID, ModelID: modelID, Name: interfaceName})
 }
 
 func generateLinkFileWithPropertyAndPolicy(vendorID, modelID, namePolicy string) string {
-	// Ensure 0x prefix - udev properties include the 0x prefix
-	if !strings.HasPrefix(vendorID, "0x") {
-		vendorID = "0x" + vendorID
-	}
-	if !strings.HasPrefix(modelID, "0x") {
-		modelID = "0x" + modelID
-	}
-
-	return fmt.Sprintf(`[Match]
-Property=ID_VENDOR_ID=%s
-Property=ID_MODEL_ID=%s
-
-[Link]
-NamePolicy=%s
-`, vendorID, modelID, namePolicy)
+	return generateLinkFile(&Rule{VendorID: vendorID, ModelID: modelID, NamePolicy: namePolicy})
 }
 
 func encodeLinkFile(content string) string {
@@ -287,8 +335,9 @@ func addLinkFileComments(yamlContent string, mc *MachineConfig) string {
 		result.WriteString(line)
 		result.WriteString("\n")
 
-		// Detect when we're entering a file entry
-		if strings.Contains(line, "- path: /etc/systemd/network/") && fileIndex < len(mc.Spec.Config.Storage.Files) {
+		// Detect when we're entering a file entry. Every file is counted, merged
+		// MachineConfigs may keep files other than .link files.
+		if strings.Contains(line, "- path: ") && fileIndex < len(mc.Spec.Config.Storage.Files) {
 			file := mc.Spec.Config.Storage.Files[fileIndex]
 
 			// Add comment with decoded content after the path line
@@ -29,7 +29,7 @@ func TestNewMachineConfigWithNames(t *testing.T) {
 			name:          "Multiple interfaces",
 			mcName:        "test-mc",
 			role:          "master",
-			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"},
+			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"},
 			namePrefix:    "ptp",
 			expectedFiles: 2,
 		},
@@ -50,6 +50,10 @@ func TestNewMachineConfigWithNames(t *testing.T) {
 				t.Errorf("Expected role %s, got %s", tt.role, mc.Metadata.Labels["machineconfiguration.openshift.io/role"])
 			}
 
+			if mc.Metadata.Labels[ManagedByLabel] != ManagedByValue {
+				t.Errorf("Expected %s label %s, got %s", ManagedByLabel, ManagedByValue, mc.Metadata.Labels[ManagedByLabel])
+			}
+
 			if len(mc.Spec.Config.Storage.Files) != tt.expectedFiles {
 				t.Errorf("Expected %d files, got %d", tt.expectedFiles, len(mc.Spec.Config.Storage.Files))
 			}
@@ -88,7 +92,7 @@ func TestNewMachineConfigWithExplicitNames(t *testing.T) {
 			name:          "Multiple interfaces in order",
 			mcName:        "test-mc",
 			role:          "master",
-			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66", "33:44:55:66:77:88"},
+			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66", "32:44:55:66:77:88"},
 			names:         []string{"ptp0", "ptp1", "ptp2"},
 			expectedFiles: 3,
 			expectError:   false,
@@ -97,7 +101,7 @@ func TestNewMachineConfigWithExplicitNames(t *testing.T) {
 			name:          "Custom names",
 			mcName:        "test-mc",
 			role:          "worker",
-			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"},
+			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"},
 			names:         []string{"timing1", "timing2"},
 			expectedFiles: 2,
 			expectError:   false,
@@ -106,7 +110,7 @@ func TestNewMachineConfigWithExplicitNames(t *testing.T) {
 			name:         "Mismatched count - too few names",
 			mcName:       "test-mc",
 			role:         "worker",
-			macAddresses: []string{"aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"},
+			macAddresses: []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"},
 			names:        []string{"ptp0"},
 			expectError:  true,
 		},
@@ -118,6 +122,22 @@ func TestNewMachineConfigWithExplicitNames(t *testing.T) {
 			names:        []string{"ptp0", "ptp1"},
 			expectError:  true,
 		},
+		{
+			name:         "Duplicate name",
+			mcName:       "test-mc",
+			role:         "worker",
+			macAddresses: []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"},
+			names:        []string{"ptp0", "ptp0"},
+			expectError:  true,
+		},
+		{
+			name:         "Duplicate MAC address",
+			mcName:       "test-mc",
+			role:         "worker",
+			macAddresses: []string{"aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF"},
+			names:        []string{"ptp0", "ptp1"},
+			expectError:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -192,7 +212,7 @@ func TestNewMachineConfigWithPolicy(t *testing.T) {
 			name:          "Multiple interfaces same policy",
 			mcName:        "test-mc",
 			role:          "master",
-			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "11:22:33:44:55:66"},
+			macAddresses:  []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66"},
 			namePolicy:    "slot",
 			expectedFiles: 2,
 		},
@@ -310,3 +330,40 @@ func TestMarshalMachineConfig(t *testing.T) {
 		t.Error("Invalid kind")
 	}
 }
+
+func TestLegacyConstructorsDuplicateMACAddress(t *testing.T) {
+	macAddresses := []string{"aa:bb:cc:dd:ee:ff", "10:22:33:44:55:66", "AA-BB-CC-DD-EE-FF"}
+
+	if _, err := NewMachineConfigWithNames("test-mc", "worker", macAddresses, "ptp"); err == nil || !strings.Contains(err.Error(), "aa:bb:cc:dd:ee:ff is given more than once") {
+		t.Errorf("NewMachineConfigWithNames(): expected duplicate MAC address error, got %v", err)
+	}
+	if _, err := NewMachineConfigWithPolicy("test-mc", "worker", macAddresses, "slot"); err == nil || !strings.Contains(err.Error(), "aa:bb:cc:dd:ee:ff is given more than once") {
+		t.Errorf("NewMachineConfigWithPolicy(): expected duplicate MAC address error, got %v", err)
+	}
+}
+
+func TestLegacyConstructorsNormalizeMACAddresses(t *testing.T) {
+	mc, err := NewMachineConfigWithPolicy("test-mc", "worker", []string{"AA-BB-CC-DD-EE-FF"}, "slot")
+	if err != nil {
+		t.Fatalf("NewMachineConfigWithPolicy() error = %v", err)
+	}
+	file := mc.Spec.Config.Storage.Files[0]
+	if file.Path != "/etc/systemd/network/10-interface-aabbccddeeff.link" {
+		t.Errorf("Expected the normalized address in the path, got %s", file.Path)
+	}
+	if !strings.Contains(file.Comment, "MACAddress=aa:bb:cc:dd:ee:ff") {
+		t.Errorf("Expected the normalized address in the link file, got %s", file.Comment)
+	}
+
+	mc, err = NewMachineConfigWithExplicitNames("test-mc", "worker", []string{"aabb.ccdd.eeff"}, []string{"ptp0"})
+	if err != nil {
+		t.Fatalf("NewMachineConfigWithExplicitNames() error = %v", err)
+	}
+	if !strings.Contains(mc.Spec.Config.Storage.Files[0].Comment, "MACAddress=aa:bb:cc:dd:ee:ff") {
+		t.Errorf("Expected the normalized address in the link file, got %s", mc.Spec.Config.Storage.Files[0].Comment)
+	}
+
+	if _, err := NewMachineConfigWithNames("test-mc", "worker", []string{"aa:bb:cc:dd:ee:fg"}, "ptp"); err == nil {
+		t.Error("Expected an invalid MAC address to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package machineconfig
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MergeMachineConfigs adds the .link files of a generated MachineConfig to those of an existing one.
+// Files matching the same interfaces as a generated file are replaced by it, other existing files
+// are kept. Giving a name already used for other interfaces, or writing a path already used for
+// other interfaces, is a conflict. Existing MachineConfigs setting fields that MachineConfig does
+// not keep, listed in Unmodelled, are refused rather than written back without them. The result
+// has the name of generated, the Ignition version of existing and the labels of both.
+func MergeMachineConfigs(existing, generated *MachineConfig) (*MachineConfig, error) {
+	if len(existing.Unmodelled) > 0 {
+		return nil, fmt.Errorf("existing MachineConfig %s sets %s, which merging would drop",
+			existing.Metadata.Name, strings.Join(existing.Unmodelled, ", "))
+	}
+
+	var existingPaths []string
+	existingLinks := make(map[string]*LinkFile)
+	for i := range existing.Spec.Config.Storage.Files {
+		file := &existing.Spec.Config.Storage.Files[i]
+		if !isLinkFile(file.Path) {
+			continue
+		}
+		link, err := parseLinkFileSource(file)
+		if err != nil {
+			return nil, fmt.Errorf("existing MachineConfig %s: %w", existing.Metadata.Name, err)
+		}
+		existingPaths = append(existingPaths, file.Path)
+		existingLinks[file.Path] = link
+	}
+
+	replaced := make(map[string]bool)
+	for i := range generated.Spec.Config.Storage.Files {
+		file := &generated.Spec.Config.Storage.Files[i]
+		link, err := parseLinkFileSource(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range existingPaths {
+			existingLink := existingLinks[path]
+			if matchKey(&existingLink.Match) == matchKey(&link.Match) {
+				replaced[path] = true
+				continue
+			}
+			if err := checkMergeConflict(file.Path, link, path, existingLink); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	files := make([]File, 0, len(existing.Spec.Config.Storage.Files)+len(generated.Spec.Config.Storage.Files))
+	for _, file := range existing.Spec.Config.Storage.Files {
+		if replaced[file.Path] {
+			continue
+		}
+		if _, ok := existingLinks[file.Path]; ok {
+			// Show the decoded contents of kept files like those of generated ones
+			file.Comment, _ = DecodeDataURL(file.Contents.Source)
+		}
+		files = append(files, file)
+	}
+	files = append(files, generated.Spec.Config.Storage.Files...)
+
+	merged := *generated
+	if existing.Spec.Config.Ignition.Version != "" {
+		merged.Spec.Config.Ignition.Version = existing.Spec.Config.Ignition.Version
+	}
+	merged.Metadata.Labels = make(map[string]string, len(existing.Metadata.Labels)+len(generated.Metadata.Labels))
+	maps.Copy(merged.Metadata.Labels, existing.Metadata.Labels)
+	maps.Copy(merged.Metadata.Labels, generated.Metadata.Labels)
+	merged.Spec.Config.Storage.Files = files
+	return &merged, nil
+}
+
+// checkMergeConflict reports a generated file that would take the name or the path of an
+// existing file matching other interfaces
+func checkMergeConflict(path string, link *LinkFile, existingPath string, existingLink *LinkFile) error {
+	if link.Link.Name != "" && link.Link.Name == existingLink.Link.Name {
+		return fmt.Errorf("name %s is already given to the interfaces matching %s in %s",
+			link.Link.Name, describeMatch(&existingLink.Match), existingPath)
+	}
+	if path == existingPath {
+		return fmt.Errorf("%s already renames the interfaces matching %s", path, describeMatch(&existingLink.Match))
+	}
+	return nil
+}
+
+func parseLinkFileSource(file *File) (*LinkFile, error) {
+	content, err := DecodeDataURL(file.Contents.Source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Path, err)
+	}
+	link, err := ParseLinkFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Path, err)
+	}
+	return link, nil
+}
+
+// matchKey identifies the interfaces a [Match] section selects, regardless of the order of
+// its MAC addresses and properties and of the case of the MAC addresses
+func matchKey(match *LinkMatch) string {
+	normalized := *match
+	normalized.MACAddress = sortedMACs(match.MACAddress)
+	normalized.PermanentMACAddress = sortedMACs(match.PermanentMACAddress)
+
+	normalized.Property = append([]string(nil), match.Property...)
+	sort.Strings(normalized.Property)
+
+	return (&LinkFile{Match: normalized}).Render()
+}
+
+func sortedMACs(list string) string {
+	macs := strings.Fields(strings.ToLower(list))
+	sort.Strings(macs)
+	return strings.Join(macs, " ")
+}
+
+// describeMatch returns the settings of a [Match] section on one line
+func describeMatch(match *LinkMatch) string {
+	lines := strings.Split(strings.TrimSpace((&LinkFile{Match: *match}).Render()), "\n")
+
+	var settings []string
+	for _, line := range lines {
+		if strings.Contains(line, "=") {
+			settings = append(settings, line)
+		}
+	}
+	return strings.Join(settings, " ")
+}
+
+// unmodelledFields lists the fields set in a MachineConfig object, decoded from JSON or YAML,
+// that the MachineConfig type does not keep. Fields set to their zero value are not listed.
+func unmodelledFields(obj map[string]interface{}) []string {
+	spec, _ := obj["spec"].(map[string]interface{})
+	config, _ := spec["config"].(map[string]interface{})
+	ignition, _ := config["ignition"].(map[string]interface{})
+	storage, _ := config["storage"].(map[string]interface{})
+
+	fields := extraFields("spec", spec, "config")
+	fields = append(fields, extraFields("spec.config", config, "ignition", "storage")...)
+	fields = append(fields, extraFields("spec.config.ignition", ignition, "version")...)
+	fields = append(fields, extraFields("spec.config.storage", storage, "files")...)
+
+	files, _ := storage["files"].([]interface{})
+	for _, rawFile := range files {
+		file, _ := rawFile.(map[string]interface{})
+		contents, _ := file["contents"].(map[string]interface{})
+		prefix := fmt.Sprintf("spec.config.storage.files[%v]", file["path"])
+		fields = append(fields, extraFields(prefix, file, "path", "mode", "overwrite", "contents")...)
+		fields = append(fields, extraFields(prefix+".contents", contents, "source")...)
+	}
+	return fields
+}
+
+// extraFields lists, in order, the keys of object other than known that are set to a non-zero value
+func extraFields(prefix string, object map[string]interface{}, known ...string) []string {
+	var fields []string
+	for key, value := range object {
+		if !slices.Contains(known, key) && !isZeroValue(value) {
+			fields = append(fields, prefix+"."+key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isZeroValue reports whether a decoded JSON or YAML value is empty, as the unset fields
+// the API server fills in often are
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, nested := range v {
+			if !isZeroValue(nested) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,235 @@
+package machineconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMergeMachineConfigs(t *testing.T) {
+	existing, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{
+		{MACAddress: "AA:BB:CC:DD:EE:01", Name: "ptp0"},
+		{MACAddress: "aa:bb:cc:dd:ee:02", Name: "ptp1"},
+		{MACAddress: "aa:bb:cc:dd:ee:03", NamePolicy: "slot"},
+	})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+	existing.Spec.Config.Storage.Files = append(existing.Spec.Config.Storage.Files, File{
+		Path:     "/etc/chrony.conf",
+		Contents: Contents{Source: "data:,server%20ntp"},
+	}, File{
+		// Edited by hand after it was generated
+		Path:     "/etc/systemd/network/10-ptp9.link",
+		Contents: Contents{Source: encodeLinkFile(generateLinkFileWithName("aa:bb:cc:dd:ee:09", "mgmt0"))},
+	})
+	existing.Spec.Config.Ignition.Version = "3.4.0"
+
+	tests := []struct {
+		name          string
+		rules         []Rule
+		expectedPaths []string
+		errContains   string
+	}{
+		{
+			name:  "New interface",
+			rules: []Rule{{MACAddress: "aa:bb:cc:dd:ee:04", Name: "ptp2"}},
+			expectedPaths: []string{
+				"/etc/systemd/network/10-ptp0.link",
+				"/etc/systemd/network/10-ptp1.link",
+				"/etc/systemd/network/10-interface-aabbccddee03.link",
+				"/etc/chrony.conf",
+				"/etc/systemd/network/10-ptp9.link",
+				"/etc/systemd/network/10-ptp2.link",
+			},
+		},
+		{
+			name:  "Renamed interface",
+			rules: []Rule{{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp5"}},
+			expectedPaths: []string{
+				"/etc/systemd/network/10-ptp1.link",
+				"/etc/systemd/network/10-interface-aabbccddee03.link",
+				"/etc/chrony.conf",
+				"/etc/systemd/network/10-ptp9.link",
+				"/etc/systemd/network/10-ptp5.link",
+			},
+		},
+		{
+			name:  "Policy replaced by a name",
+			rules: []Rule{{MACAddress: "aa:bb:cc:dd:ee:03", Name: "ptp3"}},
+			expectedPaths: []string{
+				"/etc/systemd/network/10-ptp0.link",
+				"/etc/systemd/network/10-ptp1.link",
+				"/etc/chrony.conf",
+				"/etc/systemd/network/10-ptp9.link",
+				"/etc/systemd/network/10-ptp3.link",
+			},
+		},
+		{
+			name:        "Name taken by another interface",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:04", Name: "ptp1"}},
+			errContains: "name ptp1 is already given to the interfaces matching MACAddress=aa:bb:cc:dd:ee:02",
+		},
+		{
+			name:        "Path taken by another interface",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:05", Name: "ptp9"}},
+			errContains: "10-ptp9.link already renames the interfaces matching MACAddress=aa:bb:cc:dd:ee:09",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated, err := NewMachineConfigFromRules("50-interface-rename", "worker", tt.rules)
+			if err != nil {
+				t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+			}
+
+			merged, err := MergeMachineConfigs(existing, generated)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeMachineConfigs() error = %v", err)
+			}
+
+			var paths []string
+			for _, file := range merged.Spec.Config.Storage.Files {
+				paths = append(paths, file.Path)
+				if strings.HasSuffix(file.Path, ".link") && file.Comment == "" {
+					t.Errorf("Expected decoded comment on %s", file.Path)
+				}
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.expectedPaths, ",") {
+				t.Errorf("Expected files %v, got %v", tt.expectedPaths, paths)
+			}
+
+			// The generated config uses the default version, the existing one must not be downgraded
+			if merged.Spec.Config.Ignition.Version != "3.4.0" {
+				t.Errorf("Expected the Ignition version 3.4.0 of the existing MachineConfig, got %s", merged.Spec.Config.Ignition.Version)
+			}
+		})
+	}
+}
+
+func TestMergeMachineConfigsUnmodelledFields(t *testing.T) {
+	existing := `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 50-interface-rename
+spec:
+  kernelArguments: []
+  fips: false
+  config:
+    ignition:
+      version: 3.2.0
+      config:
+        merge: []
+    systemd:
+      units:
+      - name: ptp-setup.service
+        enabled: true
+    storage:
+      files:
+      - path: /etc/systemd/network/10-ptp0.link
+        mode: 420
+        user:
+          name: root
+        contents:
+          source: data:;base64,H4sIAAAAAAAA
+          compression: gzip
+`
+	configs, err := UnmarshalMachineConfigs([]byte(existing))
+	if err != nil {
+		t.Fatalf("UnmarshalMachineConfigs() error = %v", err)
+	}
+
+	expected := []string{
+		"spec.config.systemd",
+		"spec.config.storage.files[/etc/systemd/network/10-ptp0.link].user",
+		"spec.config.storage.files[/etc/systemd/network/10-ptp0.link].contents.compression",
+	}
+	if strings.Join(configs[0].Unmodelled, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected unmodelled fields %v, got %v", expected, configs[0].Unmodelled)
+	}
+
+	generated, err := NewMachineConfigFromRules("50-interface-rename", "worker", []Rule{{MACAddress: "aa:bb:cc:dd:ee:04", Name: "ptp2"}})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+	_, err = MergeMachineConfigs(configs[0], generated)
+	if err == nil || !strings.Contains(err.Error(), "sets spec.config.systemd") || !strings.Contains(err.Error(), "merging would drop") {
+		t.Errorf("Expected the merge to be refused, got %v", err)
+	}
+}
+
+func TestFromUnstructuredUnmodelledFields(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata":   map[string]interface{}{"name": "99-worker-ptp", "resourceVersion": "12345"},
+		"spec": map[string]interface{}{
+			"kernelArguments": []interface{}{"nosmt"},
+			"extensions":      nil,
+			"osImageURL":      "",
+			"config": map[string]interface{}{
+				"ignition": map[string]interface{}{"version": "3.2.0"},
+				"storage": map[string]interface{}{
+					"files": []interface{}{map[string]interface{}{
+						"path":     "/etc/ptp.conf",
+						"mode":     int64(420),
+						"append":   []interface{}{map[string]interface{}{"source": "data:,x"}},
+						"contents": map[string]interface{}{"source": "data:,"},
+					}},
+				},
+			},
+		},
+	}}
+
+	mc, err := fromUnstructured(obj)
+	if err != nil {
+		t.Fatalf("fromUnstructured() error = %v", err)
+	}
+
+	expected := []string{"spec.kernelArguments", "spec.config.storage.files[/etc/ptp.conf].append"}
+	if strings.Join(mc.Unmodelled, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected unmodelled fields %v, got %v", expected, mc.Unmodelled)
+	}
+}
+
+func TestUpdateMachineConfigObject(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name":            "50-interface-rename",
+			"resourceVersion": "12345",
+			"labels":          map[string]interface{}{RoleLabel: "worker", "team": "ran"},
+			"annotations":     map[string]interface{}{"argocd.argoproj.io/sync-wave": "1"},
+		},
+		"spec": map[string]interface{}{"config": map[string]interface{}{}},
+	}}
+	generated, err := NewMachineConfigFromRules("50-interface-rename", "worker-ptp", []Rule{{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"}})
+	if err != nil {
+		t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+	}
+	updateMachineConfigObject(existing, generated)
+
+	labels := existing.GetLabels()
+	if labels[RoleLabel] != "worker-ptp" || labels["team"] != "ran" || labels[ManagedByLabel] != ManagedByValue {
+		t.Errorf("Expected the generated labels on top of the existing ones, got %v", labels)
+	}
+	if existing.GetAnnotations()["argocd.argoproj.io/sync-wave"] != "1" {
+		t.Errorf("Expected the annotations to be kept, got %v", existing.GetAnnotations())
+	}
+	if existing.GetResourceVersion() != "12345" {
+		t.Errorf("Expected the resource version to be kept, got %s", existing.GetResourceVersion())
+	}
+	if !reflect.DeepEqual(existing.Object["spec"], toUnstructured(generated).Object["spec"]) {
+		t.Errorf("Expected the generated spec, got %v", existing.Object["spec"])
+	}
+}
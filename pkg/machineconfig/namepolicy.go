@@ -0,0 +1,35 @@
+package machineconfig
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NamePolicySchemes are the naming schemes systemd accepts in NamePolicy=
+var NamePolicySchemes = []string{"kernel", "database", "onboard", "slot", "path", "mac", "keep"}
+
+// NormalizeNamePolicy parses an ordered list of naming schemes separated by commas or
+// whitespace and returns it space-separated, as in NamePolicy=. Unknown schemes and
+// schemes listed twice are rejected.
+func NormalizeNamePolicy(policy string) (string, error) {
+	schemes := strings.FieldsFunc(policy, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t'
+	})
+	if len(schemes) == 0 {
+		return "", fmt.Errorf("name policy must list at least one scheme")
+	}
+
+	seen := make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		if !slices.Contains(NamePolicySchemes, scheme) {
+			return "", fmt.Errorf("unknown name policy scheme %q, must be one of %s", scheme, strings.Join(NamePolicySchemes, ", "))
+		}
+		if seen[scheme] {
+			return "", fmt.Errorf("name policy scheme %s is listed twice", scheme)
+		}
+		seen[scheme] = true
+	}
+
+	return strings.Join(schemes, " "), nil
+}
@@ -0,0 +1,43 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNamePolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		errContains string
+	}{
+		{name: "Single scheme", input: "slot", expected: "slot"},
+		{name: "Comma-separated", input: "onboard,slot,path", expected: "onboard slot path"},
+		{name: "Space-separated", input: "kernel database onboard", expected: "kernel database onboard"},
+		{name: "Mixed separators", input: " slot, path mac ", expected: "slot path mac"},
+		{name: "Order kept", input: "mac,keep,path", expected: "mac keep path"},
+		{name: "Empty", input: " , ", errContains: "at least one scheme"},
+		{name: "Unknown scheme", input: "slot,pci", errContains: `unknown name policy scheme "pci"`},
+		{name: "Wrong case", input: "Slot", errContains: "unknown name policy scheme"},
+		{name: "Duplicate scheme", input: "slot,path,slot", errContains: "listed twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizeNamePolicy(tt.input)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeNamePolicy() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
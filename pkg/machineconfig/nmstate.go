@@ -0,0 +1,101 @@
+package machineconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NodeNetworkConfigurationPolicy represents a Kubernetes NMState policy, applied by the NMState
+// operator without rebooting the nodes
+type NodeNetworkConfigurationPolicy struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       NNCPSpec `yaml:"spec"`
+}
+
+type NNCPSpec struct {
+	NodeSelector map[string]string `yaml:"nodeSelector"`
+	DesiredState NMStateState      `yaml:"desiredState"`
+}
+
+type NMStateState struct {
+	Interfaces []NMStateInterface `yaml:"interfaces"`
+}
+
+// NMStateInterface is an ethernet interface identified by its MAC address. NMState gives the
+// connection profile the name and binds it to the NIC with that MAC address; the kernel
+// interface keeps its own name.
+type NMStateInterface struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`
+	State      string `yaml:"state"`
+	Identifier string `yaml:"identifier"`
+	MACAddress string `yaml:"mac-address"`
+}
+
+// NMStateRenameWarning explains that a NodeNetworkConfigurationPolicy does not rename interfaces
+const NMStateRenameWarning = "--format nmstate does not rename interfaces: NMState only gives the NetworkManager profile of each NIC the new name, " +
+	"the kernel interfaces keep their current names. Use a MachineConfig to rename them."
+
+// NewNodeNetworkConfigurationPolicy expresses rules as an NMState policy for the nodes of a role.
+// Only rules mapping a single MAC address to an explicit name can be expressed.
+func NewNodeNetworkConfigurationPolicy(name, role string, rules []Rule) (*NodeNetworkConfigurationPolicy, error) {
+	interfaces := make([]NMStateInterface, 0, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		if unsupported := nmstateUnsupported(rule); unsupported != "" {
+			return nil, fmt.Errorf("rule %d: NMState cannot express %s, only MAC address to name mappings are supported", i+1, unsupported)
+		}
+
+		interfaces = append(interfaces, NMStateInterface{
+			Name:       rule.Name,
+			Type:       "ethernet",
+			State:      "up",
+			Identifier: "mac-address",
+			MACAddress: strings.ToUpper(rule.MACAddress),
+		})
+	}
+
+	return &NodeNetworkConfigurationPolicy{
+		APIVersion: "nmstate.io/v1",
+		Kind:       "NodeNetworkConfigurationPolicy",
+		Metadata: Metadata{
+			Name:   name,
+			Labels: map[string]string{ManagedByLabel: ManagedByValue},
+		},
+		Spec: NNCPSpec{
+			NodeSelector: map[string]string{NodeRoleLabelPrefix + role: ""},
+			DesiredState: NMStateState{Interfaces: interfaces},
+		},
+	}, nil
+}
+
+// nmstateUnsupported names the first feature of a rule that has no NMState equivalent
+func nmstateUnsupported(rule *Rule) string {
+	switch {
+	case rule.NamePolicy != "":
+		return "NamePolicy"
+	case rule.MACMatch.matchesPermanent():
+		return "permanent MAC address matching"
+	case rule.VendorID != "" || rule.ModelID != "":
+		return "vendor/model matching"
+	case rule.Driver != "":
+		return "driver matching"
+	case rule.PCIPath != "":
+		return "PCI path matching"
+	case rule.Port != nil:
+		return "port matching"
+	case rule.MACAddress == "":
+		return "rules without a MAC address"
+	case len(strings.Fields(rule.MACAddress)) > 1:
+		return "several MAC addresses for one name"
+	}
+	return ""
+}
+
+func MarshalNodeNetworkConfigurationPolicy(policy *NodeNetworkConfigurationPolicy) ([]byte, error) {
+	return yaml.Marshal(policy)
+}
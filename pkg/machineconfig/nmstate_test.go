@@ -0,0 +1,64 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewNodeNetworkConfigurationPolicy(t *testing.T) {
+	policy, err := NewNodeNetworkConfigurationPolicy("50-interface-rename", "worker", []Rule{
+		{MACAddress: "aa:bb:cc:dd:ee:01", Name: "ptp0"},
+		{MACAddress: "aa:bb:cc:dd:ee:02", Name: "ptp1"},
+	})
+	if err != nil {
+		t.Fatalf("NewNodeNetworkConfigurationPolicy() error = %v", err)
+	}
+
+	if value, ok := policy.Spec.NodeSelector["node-role.kubernetes.io/worker"]; !ok || value != "" {
+		t.Errorf("Expected node selector on the worker role, got %v", policy.Spec.NodeSelector)
+	}
+
+	interfaces := policy.Spec.DesiredState.Interfaces
+	if len(interfaces) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %d", len(interfaces))
+	}
+	expected := NMStateInterface{Name: "ptp0", Type: "ethernet", State: "up", Identifier: "mac-address", MACAddress: "AA:BB:CC:DD:EE:01"}
+	if interfaces[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, interfaces[0])
+	}
+
+	data, err := MarshalNodeNetworkConfigurationPolicy(policy)
+	if err != nil {
+		t.Fatalf("MarshalNodeNetworkConfigurationPolicy() error = %v", err)
+	}
+	if !strings.Contains(string(data), "mac-address: AA:BB:CC:DD:EE:02\n") {
+		t.Errorf("Expected mac-address in output, got:\n%s", data)
+	}
+}
+
+func TestNewNodeNetworkConfigurationPolicyUnsupported(t *testing.T) {
+	port := 0
+
+	tests := []struct {
+		name        string
+		rule        Rule
+		errContains string
+	}{
+		{name: "NamePolicy", rule: Rule{MACAddress: "aa:bb:cc:dd:ee:01", NamePolicy: "mac"}, errContains: "NamePolicy"},
+		{name: "Permanent MAC", rule: Rule{MACAddress: "aa:bb:cc:dd:ee:01", MACMatch: MACMatchPermanent, Name: "ptp0"}, errContains: "permanent MAC"},
+		{name: "Vendor/model", rule: Rule{VendorID: "0x8086", ModelID: "0x1593", Name: "ptp0"}, errContains: "vendor/model"},
+		{name: "Driver", rule: Rule{Driver: "ice", Name: "ptp0"}, errContains: "driver"},
+		{name: "PCI path", rule: Rule{PCIPath: "pci-0000:3b:00.0", Name: "ptp0"}, errContains: "PCI path"},
+		{name: "Port", rule: Rule{Port: &port, Name: "ptp0"}, errContains: "port"},
+		{name: "MAC list", rule: Rule{MACAddress: "aa:bb:cc:dd:ee:01 aa:bb:cc:dd:ee:02", Name: "ptp0"}, errContains: "several MAC addresses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNodeNetworkConfigurationPolicy("50-interface-rename", "worker", []Rule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
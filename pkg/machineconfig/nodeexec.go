@@ -0,0 +1,331 @@
+package machineconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// DefaultNodeExecTimeout bounds scheduling the debug pod, pulling its image and running the command
+	DefaultNodeExecTimeout = 5 * time.Minute
+
+	// The debug image defaults to the tools image oc debug uses. It is part of the release payload,
+	// so it is mirrored on disconnected clusters, and the imagestream references it by digest.
+	toolsImageNamespace = "openshift"
+	toolsImageStream    = "tools"
+	toolsImageTag       = "latest"
+
+	debugNamespacePrefix = "ocp-rename-interfaces-debug-"
+
+	debugContainerName = "debug"
+	debugHostMount     = "/host"
+	// debugPodLifetime keeps an orphaned debug pod from running forever if cleanup fails
+	debugPodLifetime   = "3600"
+	debugPollInterval  = 2 * time.Second
+	debugDeleteTimeout = 30 * time.Second
+)
+
+var imageStreamGVR = schema.GroupVersionResource{
+	Group:    "image.openshift.io",
+	Version:  "v1",
+	Resource: "imagestreams",
+}
+
+// debugNamespaceLabels let privileged pods run in the temporary debug namespace, and keep the
+// OpenShift label synchronizer from lowering its Pod Security level again
+var debugNamespaceLabels = map[string]string{
+	"pod-security.kubernetes.io/enforce":             "privileged",
+	"pod-security.kubernetes.io/audit":               "privileged",
+	"pod-security.kubernetes.io/warn":                "privileged",
+	"security.openshift.io/scc.podSecurityLabelSync": "false",
+	ManagedByLabel: ManagedByValue,
+}
+
+// NodeExecOptions controls RunOnNode
+type NodeExecOptions struct {
+	// Image of the debug pod, the cluster's tools image when empty. It only needs chroot and sleep.
+	Image string
+	// Namespace of the debug pod, which must allow privileged pods. When empty, a temporary
+	// namespace is created and deleted afterwards.
+	Namespace string
+	Timeout   time.Duration
+}
+
+// RunOnNode runs a command in the host root filesystem of a node, like oc debug node does: a privileged
+// pod mounting the host root is scheduled on the node in a temporary namespace, the command is executed
+// in it through chroot and the pod and namespace are deleted afterwards, also when ctx is canceled.
+// It returns the command's stdout.
+func RunOnNode(ctx context.Context, kubeconfigPath, nodeName string, opts NodeExecOptions, command ...string) (string, error) {
+	opts = opts.withDefaults()
+
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return "", err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	if opts.Image == "" {
+		if opts.Image, err = toolsImage(ctx, kubeconfigPath); err != nil {
+			return "", err
+		}
+	}
+	if opts.Namespace == "" {
+		if opts.Namespace, err = createDebugNamespace(ctx, clientset); err != nil {
+			return "", err
+		}
+		defer deleteDebugNamespace(clientset, opts.Namespace)
+	}
+
+	pod, err := clientset.CoreV1().Pods(opts.Namespace).Create(ctx, newDebugPod(nodeName, &opts), metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create debug pod on node %s: %w", nodeName, err)
+	}
+	defer deleteDebugPod(clientset, pod)
+
+	if err := waitForDebugPod(ctx, clientset, pod); err != nil {
+		return "", fmt.Errorf("debug pod %s/%s on node %s: %w", pod.Namespace, pod.Name, nodeName, err)
+	}
+
+	chrootCommand := append([]string{"chroot", debugHostMount}, command...)
+	return execInPod(ctx, config, clientset, pod, chrootCommand)
+}
+
+func (o NodeExecOptions) withDefaults() NodeExecOptions {
+	if o.Timeout == 0 {
+		o.Timeout = DefaultNodeExecTimeout
+	}
+	return o
+}
+
+// toolsImage returns the image the tools imagestream of the openshift namespace points to
+func toolsImage(ctx context.Context, kubeconfigPath string) (string, error) {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return "", err
+	}
+
+	stream, err := dynamicClient.Resource(imageStreamGVR).Namespace(toolsImageNamespace).Get(ctx, toolsImageStream, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the debug image from imagestream %s/%s, set one explicitly: %w", toolsImageNamespace, toolsImageStream, err)
+	}
+	return imageStreamTagReference(stream, toolsImageTag)
+}
+
+// imageStreamTagReference returns the image, by digest, that a tag of an imagestream currently points to
+func imageStreamTagReference(stream *unstructured.Unstructured, tag string) (string, error) {
+	tags, _, _ := unstructured.NestedSlice(stream.Object, "status", "tags")
+	for _, rawTag := range tags {
+		tagMap, ok := rawTag.(map[string]interface{})
+		if !ok || tagMap["tag"] != tag {
+			continue
+		}
+		items, _, _ := unstructured.NestedSlice(tagMap, "items")
+		if len(items) == 0 {
+			break
+		}
+		if item, ok := items[0].(map[string]interface{}); ok {
+			if reference, _ := item["dockerImageReference"].(string); reference != "" {
+				return reference, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("imagestream %s/%s has no image for tag %s", stream.GetNamespace(), stream.GetName(), tag)
+}
+
+// createDebugNamespace creates a temporary namespace allowing privileged pods on every node, as
+// oc debug does, and waits for its default service account, without which pods are rejected
+func createDebugNamespace(ctx context.Context, clientset kubernetes.Interface) (string, error) {
+	namespace, err := clientset.CoreV1().Namespaces().Create(ctx, newDebugNamespace(), metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create debug namespace: %w", err)
+	}
+
+	err = wait.PollUntilContextCancel(ctx, debugPollInterval, true, func(ctx context.Context) (bool, error) {
+		_, err := clientset.CoreV1().ServiceAccounts(namespace.Name).Get(ctx, "default", metav1.GetOptions{})
+		return err == nil, nil
+	})
+	if err != nil {
+		deleteDebugNamespace(clientset, namespace.Name)
+		return "", fmt.Errorf("debug namespace %s: default service account not created: %w", namespace.Name, err)
+	}
+	return namespace.Name, nil
+}
+
+func newDebugNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: debugNamespacePrefix,
+			Labels:       maps.Clone(debugNamespaceLabels),
+			// An empty project node selector lets the debug pod run on any node, control plane included
+			Annotations: map[string]string{"openshift.io/node-selector": ""},
+		},
+	}
+}
+
+// newDebugPod builds a privileged pod pinned to a node, sharing its network and PID namespaces
+// and mounting its root filesystem, tolerating every taint so it also runs on control plane nodes
+func newDebugPod(nodeName string, opts *NodeExecOptions) *corev1.Pod {
+	privileged := true
+	var rootUser int64
+	hostPathType := corev1.HostPathDirectory
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "ocp-rename-interfaces-debug-",
+			Namespace:    opts.Namespace,
+			Labels:       map[string]string{ManagedByLabel: ManagedByValue},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			HostNetwork:   true,
+			HostPID:       true,
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    debugContainerName,
+				Image:   opts.Image,
+				Command: []string{"sleep", debugPodLifetime},
+				SecurityContext: &corev1.SecurityContext{
+					Privileged: &privileged,
+					RunAsUser:  &rootUser,
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "host", MountPath: debugHostMount}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "host",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/", Type: &hostPathType},
+				},
+			}},
+		},
+	}
+}
+
+// errDebugPodTerminated is returned for a debug pod that stopped before the command could run in it
+var errDebugPodTerminated = errors.New("pod terminated")
+
+// waitForDebugPod waits until the debug container runs. A pod that terminated fails at once,
+// the reason a pod is still waiting is only reported if it does not start before the timeout.
+func waitForDebugPod(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) error {
+	var lastErr error
+
+	err := wait.PollUntilContextCancel(ctx, debugPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get pod: %w", err)
+		}
+
+		var running bool
+		running, lastErr = debugPodRunning(current)
+		if errors.Is(lastErr, errDebugPodTerminated) {
+			return false, lastErr
+		}
+		return running, nil
+	})
+
+	if wait.Interrupted(err) {
+		if lastErr != nil {
+			return fmt.Errorf("not running before timeout: %w", lastErr)
+		}
+		return fmt.Errorf("not running before timeout: %w", err)
+	}
+	return err
+}
+
+// debugPodRunning reports whether the debug container is running. The returned error explains
+// why it is not, e.g. an image pull failure, and wraps errDebugPodTerminated if it never will.
+func debugPodRunning(pod *corev1.Pod) (bool, error) {
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		return true, nil
+	case corev1.PodFailed, corev1.PodSucceeded:
+		return false, fmt.Errorf("%w with phase %s %s", errDebugPodTerminated, pod.Status.Phase, pod.Status.Message)
+	}
+
+	for i := range pod.Status.ContainerStatuses {
+		if waiting := pod.Status.ContainerStatuses[i].State.Waiting; waiting != nil {
+			return false, fmt.Errorf("container waiting: %s %s", waiting.Reason, waiting.Message)
+		}
+	}
+	for i := range pod.Status.Conditions {
+		condition := &pod.Status.Conditions[i]
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return false, fmt.Errorf("pod not scheduled: %s", condition.Message)
+		}
+	}
+
+	return false, fmt.Errorf("pod is %s", pod.Status.Phase)
+}
+
+func execInPod(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, pod *corev1.Pod, command []string) (string, error) {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: debugContainerName,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return "", fmt.Errorf("failed to run %q on node %s: %w (stderr: %s)",
+			strings.Join(command, " "), pod.Spec.NodeName, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// deleteDebugPod removes the debug pod with its own timeout, since the caller's context may be canceled
+func deleteDebugPod(clientset kubernetes.Interface, pod *corev1.Pod) {
+	ctx, cancel := context.WithTimeout(context.Background(), debugDeleteTimeout)
+	defer cancel()
+
+	var gracePeriod int64
+	err := clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to delete debug pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
+	}
+}
+
+// deleteDebugNamespace removes the temporary debug namespace with its own timeout, since the caller's
+// context may be canceled
+func deleteDebugNamespace(clientset kubernetes.Interface, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), debugDeleteTimeout)
+	defer cancel()
+
+	if err := clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to delete debug namespace %s: %v\n", name, err)
+	}
+}
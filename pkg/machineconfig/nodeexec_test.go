@@ -0,0 +1,172 @@
+package machineconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewDebugPod(t *testing.T) {
+	opts := NodeExecOptions{Image: "quay.io/example/tools@sha256:0123", Namespace: "ocp-rename-interfaces-debug-x7k2p"}.withDefaults()
+	pod := newDebugPod("worker-0", &opts)
+
+	if pod.Namespace != opts.Namespace {
+		t.Errorf("Expected namespace %s, got %s", opts.Namespace, pod.Namespace)
+	}
+	if pod.Spec.NodeName != "worker-0" {
+		t.Errorf("Expected node worker-0, got %s", pod.Spec.NodeName)
+	}
+	if !pod.Spec.HostNetwork || !pod.Spec.HostPID {
+		t.Error("Expected debug pod to share the host network and PID namespaces")
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Operator != corev1.TolerationOpExists {
+		t.Errorf("Expected debug pod to tolerate every taint, got %v", pod.Spec.Tolerations)
+	}
+
+	container := pod.Spec.Containers[0]
+	if container.Image != opts.Image {
+		t.Errorf("Expected image %s, got %s", opts.Image, container.Image)
+	}
+	if container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+		t.Error("Expected debug container to be privileged")
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != debugHostMount {
+		t.Errorf("Expected host root mounted at %s, got %v", debugHostMount, container.VolumeMounts)
+	}
+	if pod.Spec.Volumes[0].HostPath == nil || pod.Spec.Volumes[0].HostPath.Path != "/" {
+		t.Errorf("Expected host root volume, got %v", pod.Spec.Volumes[0])
+	}
+}
+
+func TestNodeExecOptionsWithDefaults(t *testing.T) {
+	opts := NodeExecOptions{Image: "quay.io/example/tools:latest"}.withDefaults()
+
+	if opts.Image != "quay.io/example/tools:latest" {
+		t.Errorf("Expected image to be kept, got %s", opts.Image)
+	}
+	if opts.Namespace != "" {
+		t.Errorf("Expected no namespace, so that a temporary one is created, got %s", opts.Namespace)
+	}
+	if opts.Timeout != DefaultNodeExecTimeout {
+		t.Errorf("Expected timeout %s, got %s", DefaultNodeExecTimeout, opts.Timeout)
+	}
+}
+
+func TestDebugPodRunning(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      corev1.PodStatus
+		wantRunning bool
+		terminated  bool
+		errContains string
+	}{
+		{
+			name:        "running",
+			status:      corev1.PodStatus{Phase: corev1.PodRunning},
+			wantRunning: true,
+		},
+		{
+			name: "image pull failure",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			},
+			errContains: "ImagePullBackOff",
+		},
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Message: "node(s) didn't match Pod's node affinity",
+				}},
+			},
+			errContains: "not scheduled",
+		},
+		{
+			name:        "failed",
+			status:      corev1.PodStatus{Phase: corev1.PodFailed, Message: "Pod was rejected"},
+			terminated:  true,
+			errContains: "Pod was rejected",
+		},
+		{
+			name:        "succeeded",
+			status:      corev1.PodStatus{Phase: corev1.PodSucceeded},
+			terminated:  true,
+			errContains: "pod terminated with phase Succeeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running, err := debugPodRunning(&corev1.Pod{Status: tt.status})
+
+			if running != tt.wantRunning {
+				t.Errorf("Expected running %v, got %v", tt.wantRunning, running)
+			}
+			if tt.errContains == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.errContains != "" && (err == nil || !strings.Contains(err.Error(), tt.errContains)) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+			// Terminated pods stop the wait at once, the others are polled until the timeout
+			if errors.Is(err, errDebugPodTerminated) != tt.terminated {
+				t.Errorf("Expected terminated %v, got %v", tt.terminated, err)
+			}
+		})
+	}
+}
+
+func TestNewDebugNamespace(t *testing.T) {
+	namespace := newDebugNamespace()
+
+	if namespace.GenerateName != debugNamespacePrefix {
+		t.Errorf("Expected generated name with prefix %s, got %q", debugNamespacePrefix, namespace.GenerateName)
+	}
+	for _, label := range []string{"pod-security.kubernetes.io/enforce", "pod-security.kubernetes.io/audit", "pod-security.kubernetes.io/warn"} {
+		if namespace.Labels[label] != "privileged" {
+			t.Errorf("Expected %s=privileged, got %q", label, namespace.Labels[label])
+		}
+	}
+	if namespace.Labels["security.openshift.io/scc.podSecurityLabelSync"] != "false" {
+		t.Error("Expected Pod Security label synchronization to be disabled")
+	}
+	if selector, ok := namespace.Annotations["openshift.io/node-selector"]; !ok || selector != "" {
+		t.Errorf("Expected an empty project node selector, got %q", selector)
+	}
+}
+
+func TestImageStreamTagReference(t *testing.T) {
+	stream := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "tools", "namespace": "openshift"},
+		"status": map[string]interface{}{
+			"tags": []interface{}{
+				map[string]interface{}{"tag": "4.14", "items": []interface{}{
+					map[string]interface{}{"dockerImageReference": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111"},
+				}},
+				map[string]interface{}{"tag": "latest", "items": []interface{}{
+					map[string]interface{}{"dockerImageReference": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222"},
+					map[string]interface{}{"dockerImageReference": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0000"},
+				}},
+			},
+		},
+	}}
+
+	image, err := imageStreamTagReference(stream, "latest")
+	if err != nil {
+		t.Fatalf("imageStreamTagReference() error = %v", err)
+	}
+	if image != "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222" {
+		t.Errorf("Expected the current image of tag latest, got %s", image)
+	}
+
+	if _, err := imageStreamTagReference(stream, "missing"); err == nil || !strings.Contains(err.Error(), "no image for tag missing") {
+		t.Errorf("Expected missing tag error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package machineconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pciAddressPattern matches a PCI address in domain:bus:device.function form (e.g. 0000:3b:00.0)
+var pciAddressPattern = regexp.MustCompile(`^[0-9a-fA-F]{4}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]`)
+
+// NewMachineConfigWithPathAndName creates a MachineConfig matching interfaces by PCI path (systemd Path=)
+// with explicit names. The names slice must have the same length as pciPaths, and they are matched in order.
+func NewMachineConfigWithPathAndName(name, role string, pciPaths, names []string) (*MachineConfig, error) {
+	if len(pciPaths) != len(names) {
+		return nil, fmt.Errorf("number of PCI paths (%d) must match number of names (%d)", len(pciPaths), len(names))
+	}
+
+	rules := make([]Rule, 0, len(pciPaths))
+	for i, pciPath := range pciPaths {
+		normalized, err := NormalizePCIPath(pciPath)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{PCIPath: normalized, Name: names[i]})
+	}
+
+	return NewMachineConfigFromRules(name, role, rules)
+}
+
+// NewMachineConfigWithPathAndPolicy creates a MachineConfig matching interfaces by PCI path (systemd Path=) with NamePolicy
+func NewMachineConfigWithPathAndPolicy(name, role string, pciPaths []string, namePolicy string) (*MachineConfig, error) {
+	rules := make([]Rule, 0, len(pciPaths))
+	for _, pciPath := range pciPaths {
+		normalized, err := NormalizePCIPath(pciPath)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{PCIPath: normalized, NamePolicy: namePolicy})
+	}
+
+	return NewMachineConfigFromRules(name, role, rules)
+}
+
+// NormalizePCIPath converts a PCI address or udev ID_PATH into the form matched by systemd Path=.
+// A bare address such as 0000:3b:00.0 gets the pci- prefix; values that already carry it are kept.
+func NormalizePCIPath(pciPath string) (string, error) {
+	pciPath = strings.TrimSpace(pciPath)
+	if pciPath == "" {
+		return "", fmt.Errorf("PCI path must not be empty")
+	}
+
+	address := strings.TrimPrefix(pciPath, "pci-")
+	if !pciAddressPattern.MatchString(address) {
+		return "", fmt.Errorf("invalid PCI path %q: expected a PCI address such as pci-0000:3b:00.0", pciPath)
+	}
+
+	return "pci-" + strings.ToLower(address), nil
+}
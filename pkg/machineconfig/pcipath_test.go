@@ -0,0 +1,120 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMachineConfigWithPathAndName(t *testing.T) {
+	tests := []struct {
+		name          string
+		pciPaths      []string
+		names         []string
+		expectedPaths []string
+		expectError   bool
+	}{
+		{
+			name:          "Single interface",
+			pciPaths:      []string{"pci-0000:3b:00.0"},
+			names:         []string{"ptp0"},
+			expectedPaths: []string{"/etc/systemd/network/10-ptp0.link"},
+		},
+		{
+			name:          "Bare PCI addresses",
+			pciPaths:      []string{"0000:3b:00.0", "0000:3b:00.1"},
+			names:         []string{"ptp0", "ptp1"},
+			expectedPaths: []string{"/etc/systemd/network/10-ptp0.link", "/etc/systemd/network/10-ptp1.link"},
+		},
+		{
+			name:        "Mismatched count",
+			pciPaths:    []string{"pci-0000:3b:00.0", "pci-0000:3b:00.1"},
+			names:       []string{"ptp0"},
+			expectError: true,
+		},
+		{
+			name:        "Invalid PCI path",
+			pciPaths:    []string{"eth0"},
+			names:       []string{"ptp0"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, err := NewMachineConfigWithPathAndName("test-mc", "worker", tt.pciPaths, tt.names)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewMachineConfigWithPathAndName() error = %v", err)
+			}
+
+			for i, file := range mc.Spec.Config.Storage.Files {
+				if file.Path != tt.expectedPaths[i] {
+					t.Errorf("Expected path %s, got %s", tt.expectedPaths[i], file.Path)
+				}
+				if !strings.Contains(file.Comment, "Path=pci-") {
+					t.Errorf("Expected Path= match in content, got %s", file.Comment)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMachineConfigWithPathAndPolicy(t *testing.T) {
+	mc, err := NewMachineConfigWithPathAndPolicy("test-mc", "worker", []string{"pci-0000:3b:00.0"}, "slot")
+	if err != nil {
+		t.Fatalf("NewMachineConfigWithPathAndPolicy() error = %v", err)
+	}
+
+	file := mc.Spec.Config.Storage.Files[0]
+	expectedPath := "/etc/systemd/network/10-interface-pci-0000-3b-00-0.link"
+	if file.Path != expectedPath {
+		t.Errorf("Expected path %s, got %s", expectedPath, file.Path)
+	}
+
+	expectedContent := "[Match]\nPath=pci-0000:3b:00.0\n\n[Link]\nNamePolicy=slot\n"
+	if file.Comment != expectedContent {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expectedContent, file.Comment)
+	}
+}
+
+func TestNormalizePCIPath(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{input: "pci-0000:3b:00.0", expected: "pci-0000:3b:00.0"},
+		{input: "0000:3B:00.1", expected: "pci-0000:3b:00.1"},
+		{input: " pci-0000:00:1f.6 ", expected: "pci-0000:00:1f.6"},
+		{input: "", expectError: true},
+		{input: "3b:00.0", expectError: true},
+		{input: "enp59s0f0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := NormalizePCIPath(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NormalizePCIPath() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
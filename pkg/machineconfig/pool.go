@@ -0,0 +1,369 @@
+package machineconfig
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+var machineConfigPoolGVR = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigpools",
+}
+
+const (
+	// DefaultWaitInterval is how often the MachineConfigPool is polled while waiting for a rollout
+	DefaultWaitInterval = 15 * time.Second
+
+	nodeCurrentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	nodeDesiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	nodeStateAnnotation         = "machineconfiguration.openshift.io/state"
+)
+
+// PoolStatus summarizes the rollout state of a MachineConfigPool
+type PoolStatus struct {
+	Name string
+	// RenderedConfig is the rendered MachineConfig the pool is moving to (spec.configuration.name)
+	RenderedConfig string
+	// CurrentConfig is the rendered MachineConfig all nodes of the pool run (status.configuration.name)
+	CurrentConfig string
+	// Sources lists the MachineConfigs merged into RenderedConfig
+	Sources               []string
+	MachineConfigSelector *metav1.LabelSelector
+	NodeSelector          *metav1.LabelSelector
+	// Paused pools render new configs but do not roll them out to their nodes
+	Paused               bool
+	MachineCount         int64
+	UpdatedMachineCount  int64
+	DegradedMachineCount int64
+	Updated              bool
+	Degraded             bool
+	DegradedMessage      string
+}
+
+// NodeUpdateState is the Machine Config Daemon state of a single node
+type NodeUpdateState struct {
+	Name          string
+	CurrentConfig string
+	DesiredConfig string
+	State         string
+}
+
+// WaitOptions controls WaitForPoolRollout
+type WaitOptions struct {
+	Pool          string
+	MachineConfig string
+	// PreviousRenderedConfig is the pool's rendered config before the MachineConfig was applied.
+	// When set, the rollout is only considered complete once the pool moved to a new rendered config.
+	PreviousRenderedConfig string
+	Timeout                time.Duration
+	Interval               time.Duration
+}
+
+// GetPoolStatus fetches a MachineConfigPool from the cluster
+func GetPoolStatus(ctx context.Context, kubeconfigPath, pool string) (*PoolStatus, error) {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := dynamicClient.Resource(machineConfigPoolGVR).Get(ctx, pool, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MachineConfigPool %s: %w", pool, err)
+	}
+
+	return poolStatusFromUnstructured(obj)
+}
+
+// ListMachineConfigPools fetches every MachineConfigPool of the cluster, sorted by name
+func ListMachineConfigPools(ctx context.Context, kubeconfigPath string) ([]*PoolStatus, error) {
+	dynamicClient, err := getDynamicClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := dynamicClient.Resource(machineConfigPoolGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachineConfigPools: %w", err)
+	}
+
+	pools := make([]*PoolStatus, 0, len(list.Items))
+	for i := range list.Items {
+		status, err := poolStatusFromUnstructured(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, status)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	return pools, nil
+}
+
+// ListPoolNodes returns the nodes selected by a MachineConfigPool
+func ListPoolNodes(ctx context.Context, kubeconfigPath, pool string) ([]corev1.Node, error) {
+	clientset, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := GetPoolStatus(ctx, kubeconfigPath, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return listPoolNodes(ctx, clientset, status)
+}
+
+func listPoolNodes(ctx context.Context, clientset kubernetes.Interface, status *PoolStatus) ([]corev1.Node, error) {
+	if status.NodeSelector == nil {
+		return nil, fmt.Errorf("MachineConfigPool %s has no node selector", status.Name)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(status.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node selector in MachineConfigPool %s: %w", status.Name, err)
+	}
+
+	return listNodes(ctx, clientset, selector.String())
+}
+
+// ListPoolMachineConfigs returns the MachineConfigs selected by a MachineConfigPool
+func ListPoolMachineConfigs(ctx context.Context, kubeconfigPath string, status *PoolStatus) ([]*MachineConfig, error) {
+	if status.MachineConfigSelector == nil {
+		return nil, nil
+	}
+
+	selector, err := status.machineConfigSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	return ListMachineConfigs(ctx, kubeconfigPath, selector.String())
+}
+
+// ListNodes returns the nodes matching a label selector, sorted by name
+func ListNodes(ctx context.Context, kubeconfigPath, labelSelector string) ([]corev1.Node, error) {
+	clientset, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return listNodes(ctx, clientset, labelSelector)
+}
+
+func listNodes(ctx context.Context, clientset kubernetes.Interface, labelSelector string) ([]corev1.Node, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+	return nodes.Items, nil
+}
+
+// WaitForPoolRollout waits until the pool rendered a config containing the MachineConfig and every node
+// runs it, printing per-node progress. It fails as soon as the pool reports Degraded.
+func WaitForPoolRollout(ctx context.Context, kubeconfigPath string, opts WaitOptions) error {
+	clientset, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	interval := opts.Interval
+	if interval == 0 {
+		interval = DefaultWaitInterval
+	}
+
+	nodeStates := map[string]NodeUpdateState{}
+	lastSummary := ""
+
+	err = wait.PollUntilContextTimeout(ctx, interval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		status, err := GetPoolStatus(ctx, kubeconfigPath, opts.Pool)
+		if err != nil {
+			return false, err
+		}
+
+		if summary := status.summary(); summary != lastSummary {
+			fmt.Printf("MachineConfigPool %s: %s\n", opts.Pool, summary)
+			lastSummary = summary
+		}
+
+		nodes, err := listPoolNodes(ctx, clientset, status)
+		if err != nil {
+			return false, err
+		}
+		printNodeProgress(nodes, nodeStates)
+
+		return status.rolloutComplete(&opts)
+	})
+
+	if wait.Interrupted(err) {
+		return fmt.Errorf("timed out after %s waiting for MachineConfigPool %s to update", opts.Timeout, opts.Pool)
+	}
+	return err
+}
+
+// SelectsMachineConfig reports whether the pool renders MachineConfigs carrying the given labels
+func (s *PoolStatus) SelectsMachineConfig(mcLabels map[string]string) (bool, error) {
+	if s.MachineConfigSelector == nil {
+		return false, nil
+	}
+
+	selector, err := s.machineConfigSelector()
+	if err != nil {
+		return false, err
+	}
+
+	return selector.Matches(labels.Set(mcLabels)), nil
+}
+
+func (s *PoolStatus) machineConfigSelector() (labels.Selector, error) {
+	selector, err := metav1.LabelSelectorAsSelector(s.MachineConfigSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid machineConfigSelector in MachineConfigPool %s: %w", s.Name, err)
+	}
+	return selector, nil
+}
+
+// rolloutComplete reports whether the pool finished rolling out the MachineConfig, or an error if it
+// is degraded or paused, as a paused pool does not roll out anything until it is unpaused
+func (s *PoolStatus) rolloutComplete(opts *WaitOptions) (bool, error) {
+	if s.Degraded {
+		return false, fmt.Errorf("MachineConfigPool %s is degraded: %s", s.Name, s.DegradedMessage)
+	}
+
+	done := s.rendersMachineConfig(opts) && s.Updated && s.CurrentConfig == s.RenderedConfig && s.UpdatedMachineCount == s.MachineCount
+	if !done && s.Paused {
+		return false, fmt.Errorf("MachineConfigPool %s is paused, unpause it to roll out the MachineConfig", s.Name)
+	}
+	return done, nil
+}
+
+// rendersMachineConfig reports whether the rendered config of the pool includes the applied MachineConfig
+func (s *PoolStatus) rendersMachineConfig(opts *WaitOptions) bool {
+
+	if !slices.Contains(s.Sources, opts.MachineConfig) {
+		// The controller has not rendered a config including the MachineConfig yet
+		return false
+	}
+
+	return opts.PreviousRenderedConfig == "" || s.RenderedConfig != opts.PreviousRenderedConfig
+}
+
+func (s *PoolStatus) summary() string {
+	state := "updating"
+	switch {
+	case s.Degraded:
+		state = "degraded"
+	case s.Updated:
+		state = "updated"
+	}
+	return fmt.Sprintf("%s, %d/%d machines updated, %d degraded (rendered config %s)",
+		state, s.UpdatedMachineCount, s.MachineCount, s.DegradedMachineCount, s.RenderedConfig)
+}
+
+// printNodeProgress prints nodes whose Machine Config Daemon state changed since the last poll
+func printNodeProgress(nodes []corev1.Node, previous map[string]NodeUpdateState) {
+	states := make([]NodeUpdateState, 0, len(nodes))
+	for i := range nodes {
+		states = append(states, nodeUpdateState(&nodes[i]))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	for _, state := range states {
+		if previous[state.Name] == state {
+			continue
+		}
+		previous[state.Name] = state
+
+		progress := "up to date"
+		if state.CurrentConfig != state.DesiredConfig {
+			progress = fmt.Sprintf("%s -> %s", state.CurrentConfig, state.DesiredConfig)
+		}
+		fmt.Printf("  node %s: %s (%s)\n", state.Name, state.State, progress)
+	}
+}
+
+func nodeUpdateState(node *corev1.Node) NodeUpdateState {
+	return NodeUpdateState{
+		Name:          node.Name,
+		CurrentConfig: node.Annotations[nodeCurrentConfigAnnotation],
+		DesiredConfig: node.Annotations[nodeDesiredConfigAnnotation],
+		State:         node.Annotations[nodeStateAnnotation],
+	}
+}
+
+func poolStatusFromUnstructured(obj *unstructured.Unstructured) (*PoolStatus, error) {
+	status := &PoolStatus{Name: obj.GetName()}
+
+	status.RenderedConfig, _, _ = unstructured.NestedString(obj.Object, "spec", "configuration", "name")
+	status.CurrentConfig, _, _ = unstructured.NestedString(obj.Object, "status", "configuration", "name")
+	status.MachineCount, _, _ = unstructured.NestedInt64(obj.Object, "status", "machineCount")
+	status.UpdatedMachineCount, _, _ = unstructured.NestedInt64(obj.Object, "status", "updatedMachineCount")
+	status.DegradedMachineCount, _, _ = unstructured.NestedInt64(obj.Object, "status", "degradedMachineCount")
+
+	sources, _, _ := unstructured.NestedSlice(obj.Object, "spec", "configuration", "source")
+	for _, source := range sources {
+		if ref, ok := source.(map[string]interface{}); ok {
+			if name, ok := ref["name"].(string); ok {
+				status.Sources = append(status.Sources, name)
+			}
+		}
+	}
+
+	status.Paused, _, _ = unstructured.NestedBool(obj.Object, "spec", "paused")
+
+	var err error
+	if status.NodeSelector, err = nestedLabelSelector(obj, "nodeSelector"); err != nil {
+		return nil, fmt.Errorf("invalid node selector in MachineConfigPool %s: %w", obj.GetName(), err)
+	}
+	if status.MachineConfigSelector, err = nestedLabelSelector(obj, "machineConfigSelector"); err != nil {
+		return nil, fmt.Errorf("invalid machineConfigSelector in MachineConfigPool %s: %w", obj.GetName(), err)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, rawCondition := range conditions {
+		condition, ok := rawCondition.(map[string]interface{})
+		if !ok || condition["status"] != "True" {
+			continue
+		}
+		switch condition["type"] {
+		case "Updated":
+			status.Updated = true
+		case "Degraded":
+			status.Degraded = true
+			if message, ok := condition["message"].(string); ok {
+				status.DegradedMessage = message
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// nestedLabelSelector reads a label selector from the spec of a MachineConfigPool, nil when it is not set
+func nestedLabelSelector(obj *unstructured.Unstructured, field string) (*metav1.LabelSelector, error) {
+	rawSelector, found, _ := unstructured.NestedMap(obj.Object, "spec", field)
+	if !found {
+		return nil, nil
+	}
+
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSelector, selector); err != nil {
+		return nil, err
+	}
+	return selector, nil
+}
@@ -0,0 +1,211 @@
+package machineconfig
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestPool(rendered, current string, updated, degraded bool) *unstructured.Unstructured {
+	conditionStatus := func(value bool) string {
+		if value {
+			return "True"
+		}
+		return "False"
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfigPool",
+		"metadata":   map[string]interface{}{"name": "worker"},
+		"spec": map[string]interface{}{
+			"configuration": map[string]interface{}{
+				"name": rendered,
+				"source": []interface{}{
+					map[string]interface{}{"name": "00-worker"},
+					map[string]interface{}{"name": "50-interface-rename"},
+				},
+			},
+			"machineConfigSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"machineconfiguration.openshift.io/role": "worker"},
+			},
+			"nodeSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"node-role.kubernetes.io/worker": ""},
+			},
+		},
+		"status": map[string]interface{}{
+			"configuration":        map[string]interface{}{"name": current},
+			"machineCount":         int64(3),
+			"updatedMachineCount":  int64(3),
+			"degradedMachineCount": int64(0),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Updated", "status": conditionStatus(updated)},
+				map[string]interface{}{"type": "Degraded", "status": conditionStatus(degraded), "message": "Node worker-0 is reporting: boom"},
+			},
+		},
+	}}
+}
+
+func TestPoolStatusFromUnstructured(t *testing.T) {
+	status, err := poolStatusFromUnstructured(newTestPool("rendered-worker-new", "rendered-worker-old", false, false))
+	if err != nil {
+		t.Fatalf("poolStatusFromUnstructured() error = %v", err)
+	}
+
+	if status.RenderedConfig != "rendered-worker-new" || status.CurrentConfig != "rendered-worker-old" {
+		t.Errorf("Unexpected configs: %s / %s", status.RenderedConfig, status.CurrentConfig)
+	}
+	if len(status.Sources) != 2 || status.Sources[1] != "50-interface-rename" {
+		t.Errorf("Unexpected sources: %v", status.Sources)
+	}
+	if status.MachineCount != 3 || status.UpdatedMachineCount != 3 {
+		t.Errorf("Unexpected machine counts: %d / %d", status.UpdatedMachineCount, status.MachineCount)
+	}
+	if status.NodeSelector == nil || status.NodeSelector.MatchLabels["node-role.kubernetes.io/worker"] != "" {
+		t.Errorf("Unexpected node selector: %v", status.NodeSelector)
+	}
+	if status.Updated || status.Degraded {
+		t.Errorf("Expected neither Updated nor Degraded, got %v / %v", status.Updated, status.Degraded)
+	}
+}
+
+func TestSelectsMachineConfig(t *testing.T) {
+	status, err := poolStatusFromUnstructured(newTestPool("rendered-worker-new", "rendered-worker-new", true, false))
+	if err != nil {
+		t.Fatalf("poolStatusFromUnstructured() error = %v", err)
+	}
+	if status.Paused {
+		t.Error("Expected pool not to be paused")
+	}
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "Pool role", labels: map[string]string{RoleLabel: "worker", ManagedByLabel: ManagedByValue}, expected: true},
+		{name: "Other role", labels: map[string]string{RoleLabel: "worker-ptp"}},
+		{name: "No role", labels: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := status.SelectsMachineConfig(tt.labels)
+			if err != nil {
+				t.Fatalf("SelectsMachineConfig() error = %v", err)
+			}
+			if selected != tt.expected {
+				t.Errorf("Expected selected = %v, got %v", tt.expected, selected)
+			}
+		})
+	}
+
+	// A custom pool based on worker selects both roles
+	custom, err := poolToUnstructured(NewCustomMachineConfigPool("worker-ptp", "worker"))
+	if err != nil {
+		t.Fatalf("poolToUnstructured() error = %v", err)
+	}
+	customStatus, err := poolStatusFromUnstructured(custom)
+	if err != nil {
+		t.Fatalf("poolStatusFromUnstructured() error = %v", err)
+	}
+	for _, role := range []string{"worker", "worker-ptp"} {
+		if selected, err := customStatus.SelectsMachineConfig(map[string]string{RoleLabel: role}); err != nil || !selected {
+			t.Errorf("Expected custom pool to select role %s, got %v (%v)", role, selected, err)
+		}
+	}
+}
+
+func TestRolloutComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		pool          *unstructured.Unstructured
+		machineConfig string
+		previous      string
+		paused        bool
+		expectDone    bool
+		expectError   bool
+	}{
+		{
+			name:          "Rolled out",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-new", true, false),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+			expectDone:    true,
+		},
+		{
+			name:          "Not rendered yet",
+			pool:          newTestPool("rendered-worker-old", "rendered-worker-old", true, false),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+		},
+		{
+			name:          "MachineConfig not in sources",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-new", true, false),
+			machineConfig: "50-other",
+		},
+		{
+			name:          "Nodes still updating",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-old", false, false),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+		},
+		{
+			name:          "Unchanged MachineConfig",
+			pool:          newTestPool("rendered-worker-old", "rendered-worker-old", true, false),
+			machineConfig: "50-interface-rename",
+			expectDone:    true,
+		},
+		{
+			name:          "Degraded",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-old", false, true),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+			expectError:   true,
+		},
+		{
+			name:          "Paused",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-old", false, false),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+			paused:        true,
+			expectError:   true,
+		},
+		{
+			name:          "Paused after rolling out",
+			pool:          newTestPool("rendered-worker-new", "rendered-worker-new", true, false),
+			machineConfig: "50-interface-rename",
+			previous:      "rendered-worker-old",
+			paused:        true,
+			expectDone:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := unstructured.SetNestedField(tt.pool.Object, tt.paused, "spec", "paused"); err != nil {
+				t.Fatalf("SetNestedField() error = %v", err)
+			}
+			status, err := poolStatusFromUnstructured(tt.pool)
+			if err != nil {
+				t.Fatalf("poolStatusFromUnstructured() error = %v", err)
+			}
+
+			done, err := status.rolloutComplete(&WaitOptions{MachineConfig: tt.machineConfig, PreviousRenderedConfig: tt.previous})
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("rolloutComplete() error = %v", err)
+			}
+			if done != tt.expectDone {
+				t.Errorf("Expected done = %v, got %v", tt.expectDone, done)
+			}
+		})
+	}
+}
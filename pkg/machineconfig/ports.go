@@ -0,0 +1,50 @@
+package machineconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PortKey selects how the port index of a multi-port NIC is matched. systemd .link files cannot
+// match sysfs attributes directly, so ports are identified by the suffix udev appends to
+// ID_NET_NAME_PATH (e.g. enp59s0f2 or enp59s0f0np2).
+type PortKey string
+
+const (
+	// PortKeyFunction matches the PCI function suffix (f<N>), used by NICs exposing one function per port such as the Intel E810
+	PortKeyFunction PortKey = "function"
+	// PortKeyPhysPort matches the phys_port_name suffix (np<N>), used by NICs exposing all ports behind one function
+	PortKeyPhysPort PortKey = "phys-port"
+)
+
+// ParsePortKey validates a port key, an empty value selects PortKeyFunction
+func ParsePortKey(value string) (PortKey, error) {
+	switch PortKey(strings.TrimSpace(value)) {
+	case "", PortKeyFunction:
+		return PortKeyFunction, nil
+	case PortKeyPhysPort:
+		return PortKeyPhysPort, nil
+	default:
+		return "", fmt.Errorf("invalid port key %q: must be %s or %s", value, PortKeyFunction, PortKeyPhysPort)
+	}
+}
+
+// NewMachineConfigWithPropertyAndPortNames creates a MachineConfig with Property-based matching that assigns
+// one name per port of the same card model: names[0] goes to port 0, names[1] to port 1, etc.
+func NewMachineConfigWithPropertyAndPortNames(name, role, vendorID, modelID string, names []string, portKey PortKey) (*MachineConfig, error) {
+	rules := make([]Rule, 0, len(names))
+	for i, interfaceName := range names {
+		port := i
+		rules = append(rules, Rule{VendorID: vendorID, ModelID: modelID, Port: &port, PortKey: portKey, Name: interfaceName})
+	}
+
+	return NewMachineConfigFromRules(name, role, rules)
+}
+
+// portMatch returns the ID_NET_NAME_PATH glob identifying a port
+func portMatch(portKey PortKey, port int) string {
+	if portKey == PortKeyPhysPort {
+		return fmt.Sprintf("*np%d", port)
+	}
+	return fmt.Sprintf("*f%d", port)
+}
@@ -0,0 +1,90 @@
+package machineconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewMachineConfigWithPropertyAndPortNames(t *testing.T) {
+	tests := []struct {
+		name           string
+		portKey        PortKey
+		names          []string
+		expectedSuffix string
+	}{
+		{
+			name:           "Four port card by PCI function",
+			portKey:        PortKeyFunction,
+			names:          []string{"ptp0", "ptp1", "ptp2", "ptp3"},
+			expectedSuffix: "f",
+		},
+		{
+			name:           "Two port card by phys_port_name",
+			portKey:        PortKeyPhysPort,
+			names:          []string{"ptp0", "ptp1"},
+			expectedSuffix: "np",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, err := NewMachineConfigWithPropertyAndPortNames("test-mc", "worker", "0x8086", "0x1593", tt.names, tt.portKey)
+			if err != nil {
+				t.Fatalf("NewMachineConfigWithPropertyAndPortNames() error = %v", err)
+			}
+
+			if len(mc.Spec.Config.Storage.Files) != len(tt.names) {
+				t.Fatalf("Expected %d files, got %d", len(tt.names), len(mc.Spec.Config.Storage.Files))
+			}
+
+			for i, file := range mc.Spec.Config.Storage.Files {
+				expectedPath := fmt.Sprintf("/etc/systemd/network/10-%s.link", tt.names[i])
+				if file.Path != expectedPath {
+					t.Errorf("Expected path %s, got %s", expectedPath, file.Path)
+				}
+
+				expectedMatch := fmt.Sprintf("Property=ID_NET_NAME_PATH=*%s%d\n", tt.expectedSuffix, i)
+				if !strings.Contains(file.Comment, expectedMatch) {
+					t.Errorf("Expected %q in content:\n%s", expectedMatch, file.Comment)
+				}
+				if !strings.Contains(file.Comment, "Property=ID_VENDOR_ID=0x8086\n") {
+					t.Errorf("Expected vendor match in content:\n%s", file.Comment)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePortKey(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    PortKey
+		expectError bool
+	}{
+		{input: "", expected: PortKeyFunction},
+		{input: "function", expected: PortKeyFunction},
+		{input: "phys-port", expected: PortKeyPhysPort},
+		{input: "dev_port", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParsePortKey(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParsePortKey() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
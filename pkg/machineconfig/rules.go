@@ -0,0 +1,219 @@
+package machineconfig
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// Rule describes a single interface rename: which interface to match and how to name it.
+// At least one match field must be set, and Name, NamePolicy or both. NamePolicy lists naming
+// schemes tried in order, separated by commas or whitespace; with both, Name is the fallback
+// for interfaces none of the schemes yields a name for.
+// Match fields that are set are ANDed together in the generated [Match] section.
+type Rule struct {
+	MACAddress string
+	VendorID   string
+	ModelID    string
+	Driver     string
+	PCIPath    string
+	// Port restricts the match to one port of a multi-port NIC, identified according to PortKey
+	Port       *int
+	PortKey    PortKey
+	Name       string
+	NamePolicy string
+	// MACMatch selects the address MACAddress is matched against, the current one by default
+	MACMatch MACMatch
+}
+
+// NewMachineConfigFromRules creates a MachineConfig containing one .link file per rule
+func NewMachineConfigFromRules(name, role string, rules []Rule) (*MachineConfig, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("at least one rule must be specified")
+	}
+
+	files := make([]File, 0, len(rules))
+	paths := make(map[string]int, len(rules))
+	names := make(map[string]int, len(rules))
+	macs := make(map[string]int, len(rules))
+
+	for i := range rules {
+		rule, err := rules[i].normalized()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+
+		if previous, ok := names[rule.Name]; ok && rule.Name != "" {
+			return nil, fmt.Errorf("rules %d and %d both name interfaces %s", previous+1, i+1, rule.Name)
+		}
+		names[rule.Name] = i
+
+		for _, mac := range strings.Fields(rule.MACAddress) {
+			if previous, ok := macs[mac]; ok {
+				return nil, fmt.Errorf("rules %d and %d both match MAC address %s", previous+1, i+1, mac)
+			}
+			macs[mac] = i
+		}
+
+		path := linkFilePath(rule)
+		if previous, ok := paths[path]; ok {
+			return nil, fmt.Errorf("rules %d and %d both generate %s", previous+1, i+1, path)
+		}
+		paths[path] = i
+
+		linkFile := generateLinkFile(rule)
+		files = append(files, File{
+			Path:      path,
+			Mode:      DefaultFileMode,
+			Overwrite: true,
+			Contents: Contents{
+				Source: encodeLinkFile(linkFile),
+			},
+			Comment: linkFile,
+		})
+	}
+
+	return createMachineConfig(name, role, files), nil
+}
+
+// normalized returns a copy of the rule with its MAC addresses and name policy in canonical form
+func (r *Rule) normalized() (*Rule, error) {
+	rule := *r
+	if rule.NamePolicy != "" {
+		policy, err := NormalizeNamePolicy(rule.NamePolicy)
+		if err != nil {
+			return nil, err
+		}
+		rule.NamePolicy = policy
+	}
+	if rule.MACAddress != "" {
+		mac, err := NormalizeMACAddressList(rule.MACAddress)
+		if err != nil {
+			return nil, err
+		}
+		rule.MACAddress = mac
+	}
+	return &rule, nil
+}
+
+func (r *Rule) validate() error {
+	if r.MACAddress == "" && r.VendorID == "" && r.ModelID == "" && r.Driver == "" && r.PCIPath == "" {
+		return fmt.Errorf("no match criteria specified")
+	}
+
+	if (r.VendorID == "") != (r.ModelID == "") {
+		return fmt.Errorf("vendor ID and model ID must be specified together")
+	}
+
+	if r.MACAddress != "" {
+		if _, err := ParseMACMatch(string(r.MACMatch)); err != nil {
+			return err
+		}
+	}
+
+	if r.Port != nil {
+		if *r.Port < 0 {
+			return fmt.Errorf("port index must not be negative")
+		}
+		if _, err := ParsePortKey(string(r.PortKey)); err != nil {
+			return err
+		}
+	}
+
+	if r.Name == "" && r.NamePolicy == "" {
+		return fmt.Errorf("either a name or a name policy must be specified")
+	}
+
+	if r.Name != "" {
+		return ValidateInterfaceName(r.Name)
+	}
+
+	return nil
+}
+
+func generateLinkFile(rule *Rule) string {
+	return rule.linkFile().Render()
+}
+
+// linkFile builds the .link file of a rule
+func (r *Rule) linkFile() *LinkFile {
+	file := &LinkFile{
+		Match: LinkMatch{Path: r.PCIPath, Driver: r.Driver},
+		Link:  LinkSettings{Name: r.Name, NamePolicy: strings.Fields(r.NamePolicy)},
+	}
+	if r.MACMatch.matchesCurrent() {
+		file.Match.MACAddress = r.MACAddress
+	}
+	if r.MACMatch.matchesPermanent() {
+		file.Match.PermanentMACAddress = r.MACAddress
+	}
+	if r.VendorID != "" {
+		// Ensure 0x prefix - udev properties include the 0x prefix
+		file.Match.Property = append(file.Match.Property,
+			"ID_VENDOR_ID="+ensureHexPrefix(r.VendorID),
+			"ID_MODEL_ID="+ensureHexPrefix(r.ModelID))
+	}
+	if r.Port != nil {
+		file.Match.Property = append(file.Match.Property, "ID_NET_NAME_PATH="+portMatch(r.PortKey, *r.Port))
+	}
+	return file
+}
+
+// linkFilePath returns the path of the .link file for a rule. Explicitly named
+// interfaces use the name, otherwise the match criteria make the file name unique.
+func linkFilePath(rule *Rule) string {
+	if rule.Name != "" {
+		return fmt.Sprintf("/etc/systemd/network/10-%s.link", rule.Name)
+	}
+
+	var parts []string
+	if rule.MACAddress != "" {
+		parts = append(parts, macFileNamePart(rule.MACAddress))
+	}
+	if rule.PCIPath != "" {
+		parts = append(parts, sanitizeFileNamePart(rule.PCIPath))
+	}
+	if rule.Driver != "" {
+		parts = append(parts, sanitizeFileNamePart(rule.Driver))
+	}
+	if rule.VendorID != "" {
+		parts = append(parts,
+			strings.ReplaceAll(rule.VendorID, "0x", ""),
+			strings.ReplaceAll(rule.ModelID, "0x", ""))
+	}
+	if rule.Port != nil {
+		parts = append(parts, fmt.Sprintf("port%d", *rule.Port))
+	}
+
+	return fmt.Sprintf("/etc/systemd/network/10-interface-%s.link", strings.Join(parts, "-"))
+}
+
+// macFileNamePart identifies the MAC addresses of a rule in a file name: a single address without
+// colons, or a hash of a list, whose addresses would not fit a file name
+func macFileNamePart(list string) string {
+	macs := strings.Fields(list)
+	if len(macs) == 1 {
+		return strings.ReplaceAll(macs[0], ":", "")
+	}
+
+	sort.Strings(macs)
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.Join(macs, " ")))
+
+	return fmt.Sprintf("%dmacs-%08x", len(macs), hash.Sum32())
+}
+
+func sanitizeFileNamePart(value string) string {
+	return strings.NewReplacer(":", "-", ".", "-", "/", "-", "*", "x", " ", "-").Replace(value)
+}
+
+func ensureHexPrefix(id string) string {
+	if !strings.HasPrefix(id, "0x") {
+		return "0x" + id
+	}
+	return id
+}
@@ -0,0 +1,228 @@
+package machineconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMachineConfigFromRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         []Rule
+		expectedPaths []string
+		expectError   bool
+	}{
+		{
+			name: "Mixed matching methods",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0"},
+				{VendorID: "0x8086", ModelID: "0x153a", NamePolicy: "slot"},
+				{PCIPath: "pci-0000:3b:00.0", Name: "sync0"},
+				{Driver: "igc", NamePolicy: "path"},
+			},
+			expectedPaths: []string{
+				"/etc/systemd/network/10-ptp0.link",
+				"/etc/systemd/network/10-interface-8086-153a.link",
+				"/etc/systemd/network/10-sync0.link",
+				"/etc/systemd/network/10-interface-igc.link",
+			},
+		},
+		{
+			name: "MAC with policy",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff", NamePolicy: "slot"},
+			},
+			expectedPaths: []string{"/etc/systemd/network/10-interface-aabbccddeeff.link"},
+		},
+		{
+			name:        "No rules",
+			rules:       nil,
+			expectError: true,
+		},
+		{
+			name:        "No match criteria",
+			rules:       []Rule{{Name: "ptp0"}},
+			expectError: true,
+		},
+		{
+			name:        "Vendor without model",
+			rules:       []Rule{{VendorID: "0x8086", Name: "ptp0"}},
+			expectError: true,
+		},
+		{
+			name:          "Policy with fallback name",
+			rules:         []Rule{{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0", NamePolicy: "slot,path"}},
+			expectedPaths: []string{"/etc/systemd/network/10-ptp0.link"},
+		},
+		{
+			name:        "Unknown policy scheme",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:ff", NamePolicy: "slot,pci"}},
+			expectError: true,
+		},
+		{
+			name: "Duplicate name",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0"},
+				{MACAddress: "10:22:33:44:55:66", Name: "ptp0"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Duplicate file path",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff", NamePolicy: "slot"},
+				{MACAddress: "aa:bb:cc:dd:ee:ff", NamePolicy: "path"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Normalized MAC address",
+			rules: []Rule{
+				{MACAddress: "AA-BB-CC-DD-EE-FF", NamePolicy: "slot"},
+			},
+			expectedPaths: []string{"/etc/systemd/network/10-interface-aabbccddeeff.link"},
+		},
+		{
+			name: "Multiple MAC addresses with policy",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff aa:bb:cc:dd:ee:02", NamePolicy: "slot"},
+				{MACAddress: "aa:bb:cc:dd:ee:01", NamePolicy: "slot"},
+			},
+			expectedPaths: []string{
+				"/etc/systemd/network/10-interface-2macs-fd2217c9.link",
+				"/etc/systemd/network/10-interface-aabbccddee01.link",
+			},
+		},
+		{
+			name: "MAC address list order ignored in path",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:02 aa:bb:cc:dd:ee:ff", NamePolicy: "path"},
+			},
+			expectedPaths: []string{"/etc/systemd/network/10-interface-2macs-fd2217c9.link"},
+		},
+		{
+			name: "Duplicate MAC address",
+			rules: []Rule{
+				{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0"},
+				{MACAddress: "aa:bb:cc:dd:ee:01 AA:BB:CC:DD:EE:FF", Name: "ptp1"},
+			},
+			expectError: true,
+		},
+		{
+			name:        "Invalid MAC address",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:fg", Name: "ptp0"}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid MAC match",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:ff", MACMatch: "burned-in", Name: "ptp0"}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid name",
+			rules:       []Rule{{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp-grandmaster0"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, err := NewMachineConfigFromRules("test-mc", "worker", tt.rules)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewMachineConfigFromRules() error = %v", err)
+			}
+
+			if len(mc.Spec.Config.Storage.Files) != len(tt.expectedPaths) {
+				t.Fatalf("Expected %d files, got %d", len(tt.expectedPaths), len(mc.Spec.Config.Storage.Files))
+			}
+
+			for i, file := range mc.Spec.Config.Storage.Files {
+				if file.Path != tt.expectedPaths[i] {
+					t.Errorf("Expected path %s, got %s", tt.expectedPaths[i], file.Path)
+				}
+				if file.Comment == "" {
+					t.Errorf("Expected decoded content comment for %s", file.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLinkFileMatchesExistingTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected string
+	}{
+		{
+			name:     "MAC and name",
+			rule:     Rule{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0"},
+			expected: "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:     "MAC and policy",
+			rule:     Rule{MACAddress: "aa:bb:cc:dd:ee:ff", NamePolicy: "slot"},
+			expected: "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nNamePolicy=slot\n",
+		},
+		{
+			name:     "Property and name",
+			rule:     Rule{VendorID: "8086", ModelID: "153a", Name: "ptp0"},
+			expected: "[Match]\nProperty=ID_VENDOR_ID=0x8086\nProperty=ID_MODEL_ID=0x153a\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:     "Property and policy",
+			rule:     Rule{VendorID: "0x8086", ModelID: "0x153a", NamePolicy: "path"},
+			expected: "[Match]\nProperty=ID_VENDOR_ID=0x8086\nProperty=ID_MODEL_ID=0x153a\n\n[Link]\nNamePolicy=path\n",
+		},
+		{
+			name:     "Permanent MAC and name",
+			rule:     Rule{MACAddress: "aa:bb:cc:dd:ee:ff", MACMatch: MACMatchPermanent, Name: "ptp0"},
+			expected: "[Match]\nPermanentMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:     "Current and permanent MAC",
+			rule:     Rule{MACAddress: "aa:bb:cc:dd:ee:ff", MACMatch: MACMatchBoth, Name: "ptp0"},
+			expected: "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\nPermanentMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nName=ptp0\n",
+		},
+		{
+			name:     "Policy list and fallback name",
+			rule:     Rule{MACAddress: "aa:bb:cc:dd:ee:ff", Name: "ptp0", NamePolicy: "onboard slot path"},
+			expected: "[Match]\nMACAddress=aa:bb:cc:dd:ee:ff\n\n[Link]\nNamePolicy=onboard slot path\nName=ptp0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := generateLinkFile(&tt.rule)
+			if result != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateLinkFileCombinedMatch(t *testing.T) {
+	rule := Rule{PCIPath: "pci-0000:3b:00.0", Driver: "ice", VendorID: "0x8086", ModelID: "0x1593", Name: "ptp0"}
+
+	result := generateLinkFile(&rule)
+
+	for _, expected := range []string{
+		"Path=pci-0000:3b:00.0\n",
+		"Driver=ice\n",
+		"Property=ID_VENDOR_ID=0x8086\n",
+		"Property=ID_MODEL_ID=0x1593\n",
+		"Name=ptp0\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in result:\n%s", expected, result)
+		}
+	}
+}
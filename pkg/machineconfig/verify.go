@@ -0,0 +1,203 @@
+package machineconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// VerifyStatus is the outcome of checking one rule against the interfaces of a node
+type VerifyStatus string
+
+const (
+	VerifyPass VerifyStatus = "PASS"
+	VerifyFail VerifyStatus = "FAIL"
+	// VerifySkip is reported for NamePolicy rules, whose name systemd picks on the node, and for
+	// MAC address rules whose addresses belong to other nodes
+	VerifySkip VerifyStatus = "SKIP"
+)
+
+// VerifyResult is the outcome of checking one rule against the interfaces of a node
+type VerifyResult struct {
+	Expected string
+	Status   VerifyStatus
+	Detail   string
+	// absent is set when none of the MAC addresses of the rule is on the node
+	absent bool
+}
+
+// NodeInterfaces are the network interfaces found on a node
+type NodeInterfaces struct {
+	Node       string
+	Interfaces []NetworkInterface
+}
+
+// VerifyPool checks the rules against every node of a pool. The results of each node are
+// returned in node order, followed by a failure for each MAC address rule none of whose
+// addresses is on any of the nodes.
+func VerifyPool(rules []Rule, nodes []NodeInterfaces) (results [][]VerifyResult, missing []VerifyResult) {
+	found := make([]bool, len(rules))
+	for i := range nodes {
+		nodeResults := VerifyRules(rules, nodes[i].Interfaces)
+		for j := range nodeResults {
+			found[j] = found[j] || !nodeResults[j].absent
+		}
+		results = append(results, nodeResults)
+	}
+
+	for i := range rules {
+		if !found[i] {
+			missing = append(missing, VerifyResult{
+				Expected: rules[i].Name,
+				Status:   VerifyFail,
+				Detail:   fmt.Sprintf("MAC %s not found on any node", rules[i].MACAddress),
+			})
+		}
+	}
+	return results, missing
+}
+
+// VerifyRules checks that every explicitly named interface exists on a node and that it still
+// satisfies the match criteria of its rule. MAC address rules only apply to the node that has
+// one of their addresses and are skipped on the others.
+func VerifyRules(rules []Rule, interfaces []NetworkInterface) []VerifyResult {
+	byName := make(map[string]*NetworkInterface, len(interfaces))
+	for i := range interfaces {
+		byName[interfaces[i].Name] = &interfaces[i]
+	}
+
+	results := make([]VerifyResult, 0, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+
+		// With a NamePolicy, Name is only a fallback and the interface may have any name
+		if rule.NamePolicy != "" {
+			results = append(results, VerifyResult{
+				Expected: "NamePolicy=" + rule.NamePolicy,
+				Status:   VerifySkip,
+				Detail:   "name is chosen by systemd",
+			})
+			continue
+		}
+
+		iface, ok := byName[rule.Name]
+		if rule.MACAddress != "" {
+			iface, ok = findByMAC(rule.MACAddress, interfaces)
+			if !ok {
+				results = append(results, VerifyResult{Expected: rule.Name, Status: VerifySkip, Detail: "not on this node", absent: true})
+				continue
+			}
+			if iface.Name != rule.Name {
+				results = append(results, VerifyResult{Expected: rule.Name, Status: VerifyFail,
+					Detail: fmt.Sprintf("named %s (%s)", iface.Name, describeInterface(iface))})
+				continue
+			}
+		}
+		if !ok {
+			results = append(results, VerifyResult{Expected: rule.Name, Status: VerifyFail, Detail: "interface not found"})
+			continue
+		}
+
+		if mismatches := ruleMismatches(rule, iface); len(mismatches) > 0 {
+			results = append(results, VerifyResult{Expected: rule.Name, Status: VerifyFail, Detail: strings.Join(mismatches, ", ")})
+			continue
+		}
+
+		results = append(results, VerifyResult{Expected: rule.Name, Status: VerifyPass, Detail: describeInterface(iface)})
+	}
+
+	return results
+}
+
+// findByMAC returns the interface whose current or permanent address is one of a list of MAC
+// addresses, whichever one the rule matches, so an interface matched on the wrong address is
+// still found and reported
+func findByMAC(list string, interfaces []NetworkInterface) (*NetworkInterface, bool) {
+	macs := strings.Fields(list)
+	for i := range interfaces {
+		if containsFold(macs, interfaces[i].MACAddress) ||
+			(interfaces[i].PermanentMACAddress != "" && containsFold(macs, interfaces[i].PermanentMACAddress)) {
+			return &interfaces[i], true
+		}
+	}
+	return nil, false
+}
+
+// ruleMismatches lists the match criteria of a rule that an interface does not satisfy
+func ruleMismatches(rule *Rule, iface *NetworkInterface) []string {
+	var mismatches []string
+
+	if rule.MACAddress != "" {
+		mismatches = append(mismatches, macMismatches(rule, iface)...)
+	}
+	if rule.PCIPath != "" && !matchesGlob(rule.PCIPath, iface.PCIPath) {
+		mismatches = append(mismatches, fmt.Sprintf("PCI path %s, expected %s", iface.PCIPath, rule.PCIPath))
+	}
+	if rule.Driver != "" && !matchesGlob(rule.Driver, iface.Driver) {
+		mismatches = append(mismatches, fmt.Sprintf("driver %s, expected %s", iface.Driver, rule.Driver))
+	}
+	if rule.VendorID != "" && (!strings.EqualFold(ensureHexPrefix(rule.VendorID), iface.VendorID) ||
+		!strings.EqualFold(ensureHexPrefix(rule.ModelID), iface.ModelID)) {
+		mismatches = append(mismatches, fmt.Sprintf("vendor/model %s/%s, expected %s/%s",
+			iface.VendorID, iface.ModelID, ensureHexPrefix(rule.VendorID), ensureHexPrefix(rule.ModelID)))
+	}
+	if rule.Port != nil && !matchesGlob(portMatch(rule.PortKey, *rule.Port), iface.NamePath) {
+		mismatches = append(mismatches, fmt.Sprintf("port %s, expected port %d", iface.NamePath, *rule.Port))
+	}
+
+	return mismatches
+}
+
+// macMismatches lists the addresses of an interface that the MAC addresses of a rule do not match
+func macMismatches(rule *Rule, iface *NetworkInterface) []string {
+	var mismatches []string
+	macs := strings.Fields(rule.MACAddress)
+
+	if rule.MACMatch.matchesCurrent() && !containsFold(macs, iface.MACAddress) {
+		mismatches = append(mismatches, fmt.Sprintf("MAC %s, expected %s", iface.MACAddress, rule.MACAddress))
+	}
+	if rule.MACMatch.matchesPermanent() && !containsFold(macs, iface.PermanentMACAddress) {
+		permanent := iface.PermanentMACAddress
+		if permanent == "" {
+			permanent = "unknown"
+		}
+		mismatches = append(mismatches, fmt.Sprintf("permanent MAC %s, expected %s", permanent, rule.MACAddress))
+	}
+
+	return mismatches
+}
+
+// matchesGlob reports whether a value matches any of the whitespace-separated
+// shell-style patterns, as systemd does for Driver= and Path=
+func matchesGlob(patterns, value string) bool {
+	for _, pattern := range strings.Fields(patterns) {
+		if matched, err := filepath.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeInterface(iface *NetworkInterface) string {
+	parts := []string{iface.MACAddress}
+	if iface.PermanentMACAddress != "" && iface.PermanentMACAddress != iface.MACAddress {
+		parts = append(parts, "(permanent "+iface.PermanentMACAddress+")")
+	}
+	if iface.Driver != "" {
+		parts = append(parts, iface.Driver)
+	}
+	if iface.PCIPath != "" {
+		parts = append(parts, iface.PCIPath)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,136 @@
+package machineconfig
+
+import "testing"
+
+func TestVerifyRules(t *testing.T) {
+	interfaces := ParseInterfaceDump(testInterfaceDump)
+	port0 := 0
+	port1 := 1
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected VerifyStatus
+	}{
+		{
+			name:     "MAC matches case-insensitively",
+			rule:     Rule{MACAddress: "B4:96:91:AA:BB:01", Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "MAC on another node",
+			rule:     Rule{MACAddress: "b4:96:91:aa:bb:02", Name: "ptp0"},
+			expected: VerifySkip,
+		},
+		{
+			name:     "MAC on a differently named interface",
+			rule:     Rule{MACAddress: "3c:ec:ef:00:00:01", Name: "ptp0"},
+			expected: VerifyFail,
+		},
+		{
+			name:     "MAC in a list of addresses",
+			rule:     Rule{MACAddress: "b4:96:91:aa:bb:02 b4:96:91:aa:bb:01", Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "Missing interface",
+			rule:     Rule{Driver: "ice", Name: "ptp1"},
+			expected: VerifyFail,
+		},
+		{
+			name:     "Vendor/model and port",
+			rule:     Rule{VendorID: "8086", ModelID: "0x1593", Port: &port0, PortKey: PortKeyFunction, Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "Wrong port",
+			rule:     Rule{VendorID: "0x8086", ModelID: "0x1593", Port: &port1, PortKey: PortKeyFunction, Name: "ptp0"},
+			expected: VerifyFail,
+		},
+		{
+			name:     "Driver glob and PCI path",
+			rule:     Rule{Driver: "i*", PCIPath: "pci-0000:3b:00.0", Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "Wrong driver",
+			rule:     Rule{Driver: "igc", Name: "eno1"},
+			expected: VerifyFail,
+		},
+		{
+			name:     "Permanent MAC",
+			rule:     Rule{MACAddress: "b4:96:91:aa:bb:01", MACMatch: MACMatchPermanent, Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "Current and permanent MAC",
+			rule:     Rule{MACAddress: "b4:96:91:aa:bb:01", MACMatch: MACMatchBoth, Name: "ptp0"},
+			expected: VerifyPass,
+		},
+		{
+			name:     "No permanent MAC",
+			rule:     Rule{MACAddress: "3c:ec:ef:00:00:01", MACMatch: MACMatchPermanent, Name: "eno1"},
+			expected: VerifyFail,
+		},
+		{
+			name:     "NamePolicy is skipped",
+			rule:     Rule{MACAddress: "3c:ec:ef:00:00:01", NamePolicy: "slot"},
+			expected: VerifySkip,
+		},
+		{
+			name:     "NamePolicy with fallback name is skipped",
+			rule:     Rule{MACAddress: "3c:ec:ef:00:00:01", NamePolicy: "slot", Name: "ptp0"},
+			expected: VerifySkip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := VerifyRules([]Rule{tt.rule}, interfaces)
+
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(results))
+			}
+			if results[0].Status != tt.expected {
+				t.Errorf("Expected %s, got %s (%s)", tt.expected, results[0].Status, results[0].Detail)
+			}
+		})
+	}
+}
+
+func TestVerifyPool(t *testing.T) {
+	nodes := []NodeInterfaces{
+		{Node: "worker-0", Interfaces: []NetworkInterface{{Name: "ptp0", MACAddress: "b4:96:91:aa:bb:01"}}},
+		{Node: "worker-1", Interfaces: []NetworkInterface{{Name: "ptp1", MACAddress: "b4:96:91:aa:bb:02"}}},
+	}
+	rules := []Rule{
+		{MACAddress: "b4:96:91:aa:bb:01", Name: "ptp0"},
+		{MACAddress: "b4:96:91:aa:bb:02", Name: "ptp1"},
+	}
+
+	results, missing := VerifyPool(rules, nodes)
+	expected := [][]VerifyStatus{
+		{VerifyPass, VerifySkip},
+		{VerifySkip, VerifyPass},
+	}
+	for i := range expected {
+		for j, status := range expected[i] {
+			if results[i][j].Status != status {
+				t.Errorf("%s, rule %d: expected %s, got %s (%s)", nodes[i].Node, j+1, status, results[i][j].Status, results[i][j].Detail)
+			}
+		}
+	}
+	if len(missing) != 0 {
+		t.Errorf("Expected every rule to be found, got %+v", missing)
+	}
+
+	// A MAC address on none of the nodes fails even though each node skips it
+	rules = append(rules, Rule{MACAddress: "b4:96:91:aa:bb:03", Name: "ptp2"})
+	results, missing = VerifyPool(rules, nodes)
+	if results[0][2].Status != VerifySkip || results[1][2].Status != VerifySkip {
+		t.Errorf("Expected ptp2 to be skipped on both nodes, got %s and %s", results[0][2].Status, results[1][2].Status)
+	}
+	if len(missing) != 1 || missing[0].Expected != "ptp2" || missing[0].Status != VerifyFail {
+		t.Errorf("Expected ptp2 to fail as missing, got %+v", missing)
+	}
+}